@@ -2,8 +2,12 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,21 +15,28 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
 	_ "time/tzdata"
 
 	_ "github.com/breml/rootcerts"
 	"github.com/containrrr/shoutrrr"
+	"github.com/qdm12/ddns-updater/internal/audit"
+	"github.com/qdm12/ddns-updater/internal/auth"
 	"github.com/qdm12/ddns-updater/internal/backup"
 	"github.com/qdm12/ddns-updater/internal/config"
 	"github.com/qdm12/ddns-updater/internal/data"
 	"github.com/qdm12/ddns-updater/internal/health"
+	"github.com/qdm12/ddns-updater/internal/history"
 	"github.com/qdm12/ddns-updater/internal/models"
 	jsonparams "github.com/qdm12/ddns-updater/internal/params"
+	"github.com/qdm12/ddns-updater/internal/persistence/bolt"
 	persistence "github.com/qdm12/ddns-updater/internal/persistence/json"
 	recordslib "github.com/qdm12/ddns-updater/internal/records"
+	"github.com/qdm12/ddns-updater/internal/reload"
 	"github.com/qdm12/ddns-updater/internal/resolver"
 	"github.com/qdm12/ddns-updater/internal/server"
+	settingslib "github.com/qdm12/ddns-updater/internal/settings"
 	"github.com/qdm12/ddns-updater/internal/update"
 	"github.com/qdm12/ddns-updater/pkg/publicip"
 	"github.com/qdm12/golibs/connectivity"
@@ -93,7 +104,17 @@ func main() {
 }
 
 var (
-	errShoutrrrSetup = errors.New("failed setting up Shoutrrr")
+	errShoutrrrSetup  = errors.New("failed setting up Shoutrrr")
+	errPlan           = errors.New("plan has errors")
+	errCommandUnknown = errors.New("command is unknown")
+	errOnceRequired   = errors.New("flag --once is required")
+	errUpdateFailed   = errors.New("update failed")
+	errConfigInvalid  = errors.New("configuration is invalid")
+	errIPDisagreement = errors.New("public IP providers disagree")
+	errBackupRequired = errors.New("backup zip file path is required")
+	errBackupInvalid  = errors.New("backup is invalid")
+	errImportRequired = errors.New("file path to import is required")
+	errImportFormat   = errors.New("cannot infer the format from the file extension")
 )
 
 func _main(ctx context.Context, env params.Interface, args []string, logger log.LoggerInterface,
@@ -128,8 +149,41 @@ func _main(ctx context.Context, env params.Interface, args []string, logger log.
 		PaypalUser:    "qmcgaw",
 		GithubSponsor: "qdm12",
 	}
-	for _, line := range gosplash.MakeLines(splashSettings) {
-		fmt.Println(line)
+	command := ""
+	if len(args) > 1 {
+		command = args[1]
+	}
+	var historyFormat history.Format
+	var historyFilter models.HistoryFilter
+	var importPath string
+	switch command {
+	case "", "plan", "validate", "ip", "migrate", "compact":
+	case "restore":
+		if len(args) < 3 { //nolint:gomnd
+			return fmt.Errorf("%w for the restore command", errBackupRequired)
+		}
+	case "update":
+		err = parseUpdateFlags(args[2:])
+		if err != nil {
+			return err
+		}
+	case "export":
+		historyFormat, historyFilter, err = parseExportFlags(args[2:])
+		if err != nil {
+			return err
+		}
+	case "import":
+		historyFormat, importPath, err = parseImportFlags(args[2:])
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: %s", errCommandUnknown, command)
+	}
+	if command == "" { // only show the splash for the long running program
+		for _, line := range gosplash.MakeLines(splashSettings) {
+			fmt.Println(line)
+		}
 	}
 
 	var config config.Config
@@ -146,8 +200,21 @@ func _main(ctx context.Context, env params.Interface, args []string, logger log.
 	if config.Logger.Caller {
 		options = append(options, log.SetCallerFile(true), log.SetCallerLine(true))
 	}
+	if command == "plan" || command == "ip" || command == "compact" || command == "export" {
+		// keep stdout for the command output only
+		options = append(options, log.SetWriters(os.Stderr))
+	}
 	logger.Patch(options...)
 
+	switch command {
+	case "validate":
+		return runValidate(config.Paths.JSON, logger)
+	case "ip":
+		return runIPCheck(ctx, config.Client, config.PubIP)
+	case "restore":
+		return runRestore(args[2], config.Paths, config.Backup, config.Database.Backend, logger)
+	}
+
 	sender, err := shoutrrr.CreateSender(config.Shoutrrr.Addresses...)
 	if err != nil {
 		return fmt.Errorf("%w: %w", errShoutrrrSetup, err)
@@ -162,12 +229,6 @@ func _main(ctx context.Context, env params.Interface, args []string, logger log.
 		}
 	}
 
-	persistentDB, err := persistence.NewDatabase(config.Paths.DataDir)
-	if err != nil {
-		notify(err.Error())
-		return err
-	}
-
 	jsonReader := jsonparams.NewReader(logger)
 	settings, warnings, err := jsonReader.JSONSettings(config.Paths.JSON)
 	for _, w := range warnings {
@@ -189,28 +250,64 @@ func _main(ctx context.Context, env params.Interface, args []string, logger log.
 		logger.Info("Found " + fmt.Sprint(len(settings)) + " settings to update records")
 	}
 
-	client := &http.Client{Timeout: config.Client.Timeout}
+	keys := make([]models.RecordKey, len(settings))
+	for i, s := range settings {
+		keys[i] = settingslib.Key(s)
+	}
 
-	connectivity := connectivity.NewHTTPSGetChecker(client, http.StatusOK)
-	err = connectivity.Check(ctx, "https://github.com")
+	if command == "migrate" {
+		return runMigrate(config.Paths.DataDir, config.Database, keys, logger)
+	}
+
+	persistentDB, err := openPersistentDB(config.Database, config.Paths.DataDir,
+		logger.New(log.SetComponent("database")))
 	if err != nil {
-		logger.Warn(err.Error())
+		notify(err.Error())
+		return err
+	}
+
+	err = persistentDB.Migrate(keys)
+	if err != nil {
+		err = fmt.Errorf("migrating persisted records: %w", err)
+		notify(err.Error())
+		return err
+	}
+
+	if command == "compact" {
+		return runCompact(persistentDB, timeNow())
 	}
 
 	records := make([]recordslib.Record, len(settings))
 	for i, s := range settings {
 		logger.Info("Reading history from database: domain " +
 			s.Domain() + " host " + s.Host())
-		events, err := persistentDB.GetEvents(s.Domain(), s.Host())
+		events, err := persistentDB.GetEvents(keys[i])
 		if err != nil {
 			notify(err.Error())
 			return err
 		}
-		records[i] = recordslib.New(s, events)
+		state, err := persistentDB.GetState(keys[i])
+		if err != nil {
+			notify(err.Error())
+			return err
+		}
+		records[i] = recordslib.New(s, events, state)
 	}
 
-	defer client.CloseIdleConnections()
-	db := data.NewDatabase(records, persistentDB)
+	backupConfigPath := config.Paths.JSON
+	if config.Paths.JSONFromEnv {
+		backupConfigPath = "" // no configuration file to back up
+	}
+	backupRecipients, err := backup.NewRecipients(config.Backup.Passphrase, config.Backup.Recipients)
+	if err != nil {
+		return fmt.Errorf("setting up backup encryption: %w", err)
+	}
+	backuper := backup.NewBackuper(config.Backup.Period, config.Backup.OnChange,
+		config.Backup.Keep, config.Backup.MaxAge, config.Backup.Directory, backupConfigPath,
+		filepath.Join(config.Paths.DataDir, audit.FileName), databaseFileName(config.Database.Backend), persistentDB, backupRecipients,
+		logger.New(log.SetComponent("backup")), timeNow)
+
+	db := data.NewDatabase(records, persistentDB, backuper.NotifyChange)
 	defer func() {
 		err := db.Close()
 		if err != nil {
@@ -218,6 +315,22 @@ func _main(ctx context.Context, env params.Interface, args []string, logger log.
 		}
 	}()
 
+	switch command {
+	case "export":
+		return history.Encode(os.Stdout, historyFormat, db.SelectEvents(historyFilter))
+	case "import":
+		return runImport(db, importPath, historyFormat, logger, timeNow())
+	}
+
+	client := &http.Client{Timeout: config.Client.Timeout}
+	defer client.CloseIdleConnections()
+
+	connectivity := connectivity.NewHTTPSGetChecker(client, http.StatusOK)
+	err = connectivity.Check(ctx, "https://github.com")
+	if err != nil {
+		logger.Warn(err.Error())
+	}
+
 	config.PubIP.HTTPSettings.Client = client
 
 	ipGetter, err := publicip.NewFetcher(config.PubIP.DNSSettings, config.PubIP.HTTPSettings)
@@ -230,9 +343,44 @@ func _main(ctx context.Context, env params.Interface, args []string, logger log.
 		return fmt.Errorf("creating resolver: %w", err)
 	}
 
-	updater := update.NewUpdater(db, client, notify, logger)
-	runner := update.NewRunner(db, updater, ipGetter, config.Update.Period,
-		config.IPv6.Mask, config.Update.Cooldown, logger, resolver, timeNow)
+	backoff := update.NewBackoff(config.Update.RetryInitial, config.Update.RetryMaximum)
+	updater := update.NewUpdater(db, client, backoff, notify, logger)
+	var schedule update.Scheduler = update.Periodic(config.Update.Period)
+	if config.Update.Cron != nil {
+		schedule = config.Update.Cron
+	}
+	var auditor update.Auditor
+	var auditQuerier server.AuditQuerier
+	if config.Audit.Enabled {
+		auditLog := audit.NewLog(config.Paths.DataDir, config.Audit.MaxSize)
+		auditor, auditQuerier = auditLog, auditLog
+	}
+	runner := update.NewRunner(db, updater, auditor, ipGetter, schedule, config.Update.Jitter,
+		config.IPv6.Mask, config.Update.Cooldown, config.Update.Workers,
+		config.Update.ProviderWorkers, config.Update.DryRun, logger, resolver, timeNow)
+
+	switch command {
+	case "plan":
+		return runPlan(ctx, runner)
+	case "update":
+		return runUpdateOnce(ctx, runner)
+	}
+
+	address := ":" + strconv.Itoa(int(config.Server.Port))
+	serverLogger := logger.New(log.SetComponent("http server"))
+	var authenticator server.Authenticator
+	if config.Auth.Enabled() {
+		authenticator = auth.New(config.Auth.BasicUsers, config.Auth.Tokens,
+			config.Auth.ProxyHeader, config.Auth.ProxyNetworks)
+	}
+	var tlsConfig *tls.Config
+	if config.Server.TLS.Enabled() {
+		tlsConfig, err = server.NewTLSConfig(config.Server.TLS.CertFile, config.Server.TLS.KeyFile,
+			config.Server.TLS.ClientCAFile, serverLogger)
+		if err != nil {
+			return fmt.Errorf("creating TLS configuration: %w", err)
+		}
+	}
 
 	runnerHandler, runnerCtx, runnerDone := goshutdown.NewGoRoutineHandler("runner")
 	go runner.Run(runnerCtx, runnerDone)
@@ -248,20 +396,27 @@ func _main(ctx context.Context, env params.Interface, args []string, logger log.
 	healthServerHandler, healthServerCtx, healthServerDone := goshutdown.NewGoRoutineHandler("health server")
 	go healthServer.Run(healthServerCtx, healthServerDone)
 
-	address := ":" + strconv.Itoa(int(config.Server.Port))
-	serverLogger := logger.New(log.SetComponent("http server"))
-	server := server.New(ctx, address, config.Server.RootURL, db, serverLogger, runner)
+	server := server.New(ctx, address, config.Server.RootURL, db, serverLogger,
+		runner, auditQuerier, authenticator, tlsConfig)
 	serverHandler, serverCtx, serverDone := goshutdown.NewGoRoutineHandler("server")
 	go server.Run(serverCtx, serverDone)
 	notify("Launched with " + strconv.Itoa(len(records)) + " records to watch")
 
+	reloadSignals := make(chan os.Signal, 1)
+	signal.Notify(reloadSignals, syscall.SIGHUP)
+	defer signal.Stop(reloadSignals)
+	watcherLogger := logger.New(log.SetComponent("config reload"))
+	watcher := reload.NewWatcher(config.Paths.JSON, config.Reload.WatchPeriod,
+		config.Paths.JSONFromEnv, reloadSignals,
+		jsonReader, runner, notify, watcherLogger)
+	watcherHandler, watcherCtx, watcherDone := goshutdown.NewGoRoutineHandler("config reload")
+	go watcher.Run(watcherCtx, watcherDone)
+
 	backupHandler, backupCtx, backupDone := goshutdown.NewGoRoutineHandler("backup")
-	backupLogger := logger.New(log.SetComponent("backup"))
-	go backupRunLoop(backupCtx, backupDone, config.Backup.Period, config.Paths.DataDir, config.Backup.Directory,
-		backupLogger, timeNow)
+	go backuper.Run(backupCtx, backupDone)
 
 	shutdownGroup := goshutdown.NewGroupHandler("")
-	shutdownGroup.Add(runnerHandler, healthServerHandler, serverHandler, backupHandler)
+	shutdownGroup.Add(runnerHandler, healthServerHandler, serverHandler, watcherHandler, backupHandler)
 
 	<-ctx.Done()
 
@@ -273,39 +428,409 @@ func _main(ctx context.Context, env params.Interface, args []string, logger log.
 	return nil
 }
 
-type InfoErroer interface {
-	Info(s string)
-	Error(s string)
+// runPlan prints the plan of the runner as JSON to stdout and
+// returns an error if the plan contains errors.
+func runPlan(ctx context.Context, runner *update.Runner) (err error) {
+	plan := runner.Plan(ctx)
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	err = encoder.Encode(plan)
+	if err != nil {
+		return fmt.Errorf("encoding plan: %w", err)
+	}
+
+	if len(plan.Errors) > 0 {
+		return fmt.Errorf("%w: %s", errPlan, strings.Join(plan.Errors, "; "))
+	}
+	return nil
+}
+
+func parseUpdateFlags(args []string) (err error) {
+	flagSet := flag.NewFlagSet("update", flag.ContinueOnError)
+	once := flagSet.Bool("once", false, "run a single update of all the records and exit")
+	err = flagSet.Parse(args)
+	if err != nil {
+		return fmt.Errorf("parsing update flags: %w", err)
+	}
+	if !*once {
+		return fmt.Errorf("%w for the update command", errOnceRequired)
+	}
+	return nil
 }
 
-func backupRunLoop(ctx context.Context, done chan<- struct{}, backupPeriod time.Duration,
-	dataDir, outputDir string, logger InfoErroer, timeNow func() time.Time) {
-	defer close(done)
-	if backupPeriod == 0 {
-		logger.Info("disabled")
-		return
-	}
-	logger.Info("each " + backupPeriod.String() +
-		"; writing zip files to directory " + outputDir)
-	ziper := backup.NewZiper()
-	timer := time.NewTimer(backupPeriod)
-	for {
-		fileName := "ddns-updater-backup-" + strconv.Itoa(int(timeNow().UnixNano())) + ".zip"
-		zipFilepath := filepath.Join(outputDir, fileName)
-		err := ziper.ZipFiles(
-			zipFilepath,
-			filepath.Join(dataDir, "updates.json"),
-			filepath.Join(dataDir, "config.json"),
-		)
+func parseExportFlags(args []string) (format history.Format,
+	filter models.HistoryFilter, err error) {
+	flagSet := flag.NewFlagSet("export", flag.ContinueOnError)
+	formatString := flagSet.String("format", "csv", "output format, csv or json")
+	flagSet.StringVar(&filter.RecordID, "record", "", "record identifier to export, all records if empty")
+	from := flagSet.String("from", "", "RFC3339 time from which to export IP addresses")
+	to := flagSet.String("to", "", "RFC3339 time up to which to export IP addresses")
+	err = flagSet.Parse(args)
+	if err != nil {
+		return "", filter, fmt.Errorf("parsing export flags: %w", err)
+	}
+
+	format, err = history.ParseFormat(*formatString)
+	if err != nil {
+		return "", filter, err
+	}
+
+	if *from != "" {
+		filter.From, err = time.Parse(time.RFC3339, *from)
 		if err != nil {
-			logger.Error(err.Error())
+			return "", filter, fmt.Errorf("parsing from time: %w", err)
 		}
-		select {
-		case <-timer.C:
-			timer.Reset(backupPeriod)
-		case <-ctx.Done():
-			timer.Stop()
-			return
+	}
+	if *to != "" {
+		filter.To, err = time.Parse(time.RFC3339, *to)
+		if err != nil {
+			return "", filter, fmt.Errorf("parsing to time: %w", err)
 		}
 	}
+	return format, filter, nil
+}
+
+func parseImportFlags(args []string) (format history.Format, path string, err error) {
+	flagSet := flag.NewFlagSet("import", flag.ContinueOnError)
+	formatString := flagSet.String("format", "",
+		"input format, csv or json, inferred from the file extension if empty")
+	err = flagSet.Parse(args)
+	if err != nil {
+		return "", "", fmt.Errorf("parsing import flags: %w", err)
+	}
+
+	path = flagSet.Arg(0)
+	if path == "" {
+		return "", "", fmt.Errorf("%w for the import command", errImportRequired)
+	}
+
+	inferred := *formatString == ""
+	if inferred {
+		*formatString = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	format, err = history.ParseFormat(*formatString)
+	switch {
+	case err != nil && inferred:
+		return "", "", fmt.Errorf("%w: %w, set the --format flag", errImportFormat, err)
+	case err != nil:
+		return "", "", err
+	}
+	return format, path, nil
+}
+
+// runImport merges the IP addresses history from the file at path
+// into the database.
+func runImport(db *data.Database, path string, format history.Format,
+	logger log.LoggerInterface, now time.Time) (err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	events, err := history.Decode(file, format)
+	if err != nil {
+		return fmt.Errorf("decoding %s: %w", path, err)
+	}
+
+	result, err := db.ImportEvents(events, now)
+	if err != nil {
+		return fmt.Errorf("importing history: %w", err)
+	}
+	if result.Unmatched > 0 {
+		logger.Warn(fmt.Sprintf("%d IP addresses matching no record are not imported",
+			result.Unmatched))
+	}
+	logger.Info(fmt.Sprintf("imported %d IP addresses, skipped %d duplicates",
+		result.Added, result.Duplicates))
+	return nil
+}
+
+type onceUpdater interface {
+	UpdateOnce(ctx context.Context) (errs []error)
+}
+
+// runUpdateOnce checks and updates all the records once, and returns
+// an error if any record failed to be updated, so the program exits
+// with a non zero status.
+func runUpdateOnce(ctx context.Context, runner onceUpdater) (err error) {
+	errs := runner.UpdateOnce(ctx)
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %d error(s) occurred: %w",
+			errUpdateFailed, len(errs), errors.Join(errs...))
+	}
+	return nil
+}
+
+// runValidate validates the JSON configuration and logs all the
+// warnings and errors found, returning an error if any error is found.
+func runValidate(jsonFilePath string, logger log.LoggerInterface) (err error) {
+	jsonReader := jsonparams.NewReader(logger)
+	warnings, errs := jsonReader.ValidateJSONSettings(jsonFilePath)
+	for _, warning := range warnings {
+		logger.Warn(warning)
+	}
+	for _, err := range errs {
+		logger.Error(err.Error())
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %d error(s) found", errConfigInvalid, len(errs))
+	}
+	logger.Info("configuration is valid")
+	return nil
+}
+
+// runIPCheck queries every public IP provider configured, prints the
+// results as a table to stdout and returns an error if providers disagree
+// on the public IP address.
+func runIPCheck(ctx context.Context, clientConfig config.Client,
+	pubIPConfig config.PubIP) (err error) {
+	client := &http.Client{Timeout: clientConfig.Timeout}
+	defer client.CloseIdleConnections()
+	pubIPConfig.HTTPSettings.Client = client
+
+	ipGetter, err := publicip.NewFetcher(pubIPConfig.DNSSettings, pubIPConfig.HTTPSettings)
+	if err != nil {
+		return err
+	}
+
+	checks := ipGetter.CheckAll(ctx)
+	majorityIPv4, majorityIPv6 := publicip.MajorityIPs(checks)
+
+	const minWidth, tabWidth, padding = 0, 8, 2
+	writer := tabwriter.NewWriter(os.Stdout, minWidth, tabWidth, padding, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "FETCHER\tPROVIDER\tVERSION\tRESULT\tMISMATCH\tLATENCY\tERROR")
+	for _, check := range checks {
+		result, mismatch, errString := "", "", ""
+		switch {
+		case check.Err != nil:
+			errString = check.Err.Error()
+		case check.IP != nil:
+			result = check.IP.String()
+			majorityIP := majorityIPv6
+			if check.IP.To4() != nil {
+				majorityIP = majorityIPv4
+			}
+			if !check.IP.Equal(majorityIP) {
+				mismatch = "yes"
+			}
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", check.Fetcher, check.Provider,
+			check.Version, result, mismatch, check.Latency.Round(time.Millisecond), errString)
+	}
+	err = writer.Flush()
+	if err != nil {
+		return fmt.Errorf("writing table: %w", err)
+	}
+
+	ipv4s, ipv6s := publicip.DistinctIPs(checks)
+	var disagreements []string
+	for _, ips := range [][]net.IP{ipv4s, ipv6s} {
+		if len(ips) <= 1 {
+			continue
+		}
+		ipStrings := make([]string, len(ips))
+		for i, ip := range ips {
+			ipStrings[i] = ip.String()
+		}
+		disagreements = append(disagreements, strings.Join(ipStrings, ", "))
+	}
+	if len(disagreements) > 0 {
+		return fmt.Errorf("%w: %s", errIPDisagreement, strings.Join(disagreements, "; "))
+	}
+	return nil
+}
+
+func openPersistentDB(databaseConfig config.Database, dataDir string, //nolint:ireturn
+	logger persistence.Logger) (persistentDB data.PersistentDatabase, err error) {
+	if databaseConfig.Backend == config.DatabaseBolt {
+		return bolt.NewDatabase(dataDir, databaseConfig.Retention)
+	}
+	return persistence.NewDatabase(dataDir, databaseConfig.Generations,
+		databaseConfig.Recovery, databaseConfig.Retention, logger)
+}
+
+func databaseFileName(backend string) string {
+	if backend == config.DatabaseBolt {
+		return bolt.FileName
+	}
+	return persistence.FileName
+}
+
+// runMigrate copies all the records persisted in the JSON
+// database to the bolt database, replacing existing records.
+func runMigrate(dataDir string, databaseConfig config.Database,
+	keys []models.RecordKey, logger log.LoggerInterface) (err error) {
+	jsonDB, err := persistence.NewDatabase(dataDir, databaseConfig.Generations,
+		databaseConfig.Recovery, databaseConfig.Retention, logger)
+	if err != nil {
+		return err
+	}
+	defer jsonDB.Close()
+
+	err = jsonDB.Migrate(keys)
+	if err != nil {
+		return fmt.Errorf("migrating JSON persisted records: %w", err)
+	}
+
+	records, legacy, err := jsonDB.Export()
+	if err != nil {
+		return fmt.Errorf("exporting JSON persisted records: %w", err)
+	}
+	if legacy > 0 {
+		logger.Warn(fmt.Sprintf("%d records without identifier matching none of the settings "+
+			"are not migrated", legacy))
+	}
+
+	boltDB, err := bolt.NewDatabase(dataDir, databaseConfig.Retention)
+	if err != nil {
+		return err
+	}
+
+	err = boltDB.Import(records)
+	if err != nil {
+		_ = boltDB.Close()
+		return fmt.Errorf("importing records: %w", err)
+	}
+
+	err = boltDB.Close()
+	if err != nil {
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("migrated %d records from %s to %s", len(records),
+		filepath.Join(dataDir, persistence.FileName), filepath.Join(dataDir, bolt.FileName)))
+	return nil
+}
+
+// runCompact prunes the history of all records according to the
+// retention policy, and prints the history statistics of each record.
+func runCompact(persistentDB data.PersistentDatabase, now time.Time) (err error) {
+	defer persistentDB.Close()
+
+	results, err := persistentDB.Compact(now)
+	if err != nil {
+		return fmt.Errorf("compacting history: %w", err)
+	}
+
+	const minWidth, tabWidth, padding = 0, 8, 2
+	writer := tabwriter.NewWriter(os.Stdout, minWidth, tabWidth, padding, ' ', 0)
+	fmt.Fprintln(writer, "ID\tKEPT\tPRUNED\tCHANGES\tFIRST SEEN")
+	for _, result := range results {
+		firstSeen := "N/A"
+		if !result.Stats.FirstSeen.IsZero() {
+			firstSeen = result.Stats.FirstSeen.Format(time.RFC3339)
+		}
+		fmt.Fprintf(writer, "%s\t%d\t%d\t%d\t%s\n", result.ID, result.Kept,
+			result.Pruned, result.Stats.Changes, firstSeen)
+	}
+	return writer.Flush()
+}
+
+// runRestore restores the configuration file and the database from the
+// backup zip file at zipPath, decrypting it if needed, after validating
+// them. The files replaced are kept with the backup.BeforeRestoreSuffix suffix.
+func runRestore(zipPath string, paths config.Paths, backupConfig config.Backup,
+	backend string, logger log.LoggerInterface) (err error) {
+	tempDir, err := os.MkdirTemp(paths.DataDir, "restore-")
+	if err != nil {
+		return fmt.Errorf("creating temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	encrypted, err := backup.IsEncrypted(zipPath)
+	if err != nil {
+		return err
+	} else if encrypted {
+		identities, err := backup.NewIdentities(backupConfig.Passphrase, backupConfig.IdentityFile)
+		if err != nil {
+			return fmt.Errorf("setting up backup decryption: %w", err)
+		}
+		decryptedPath := filepath.Join(tempDir, "backup.zip")
+		err = backup.Decrypt(zipPath, decryptedPath, identities)
+		if errors.Is(err, backup.ErrIdentityMissing) {
+			return fmt.Errorf("%w: set BACKUP_PASSPHRASE or BACKUP_IDENTITY_FILE", err)
+		} else if err != nil {
+			return err
+		}
+		zipPath = decryptedPath
+	}
+
+	configFileName := filepath.Base(paths.JSON)
+	names, err := backup.Extract(zipPath, tempDir,
+		configFileName, persistence.FileName, bolt.FileName, audit.FileName)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errBackupInvalid, err)
+	}
+
+	sourceToDestination := make(map[string]string, len(names))
+	databaseFound := false
+	for _, name := range names {
+		source := filepath.Join(tempDir, name)
+		switch name {
+		case configFileName:
+			err = validateBackupConfig(source, logger)
+			sourceToDestination[source] = paths.JSON
+		case audit.FileName:
+			sourceToDestination[source] = filepath.Join(paths.DataDir, name)
+		default:
+			err = validateBackupDatabase(tempDir, name, logger)
+			sourceToDestination[source] = filepath.Join(paths.DataDir, name)
+			databaseFound = true
+			if name != databaseFileName(backend) {
+				logger.Warn("restoring database file " + name + " which is not used by the " +
+					backend + " database backend")
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", errBackupInvalid, name, err)
+		}
+	}
+	if !databaseFound {
+		return fmt.Errorf("%w: no database file found", errBackupInvalid)
+	}
+
+	err = backup.Replace(sourceToDestination)
+	if err != nil {
+		return fmt.Errorf("restoring backup: %w", err)
+	}
+	for _, destination := range sourceToDestination {
+		logger.Info("restored " + destination)
+	}
+	return nil
+}
+
+func validateBackupConfig(path string, logger log.LoggerInterface) (err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	warnings, errs := jsonparams.ValidateJSON(b)
+	for _, warning := range warnings {
+		logger.Warn(warning)
+	}
+	return errors.Join(errs...)
+}
+
+func validateBackupDatabase(dir, fileName string, logger persistence.Logger) (err error) {
+	var database interface {
+		Check() error
+		Close() error
+	}
+	if fileName == bolt.FileName {
+		database, err = bolt.NewDatabase(dir, models.Retention{})
+	} else {
+		database, err = persistence.NewDatabase(dir, 0, false, models.Retention{}, logger)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = database.Check()
+	closeErr := database.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
 }
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/qdm12/golibs/params"
+	"github.com/qdm12/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testOnceUpdater struct {
+	errs  []error
+	calls int
+}
+
+func (u *testOnceUpdater) UpdateOnce(context.Context) (errs []error) {
+	u.calls++
+	return u.errs
+}
+
+func Test_runUpdateOnce(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+
+	testCases := map[string]struct {
+		errs       []error
+		errWrapped error
+		errMessage string
+	}{
+		"all records updated": {},
+		"record failed": {
+			errs:       []error{errTest},
+			errWrapped: errUpdateFailed,
+			errMessage: "update failed: 1 error(s) occurred: test error",
+		},
+		"records failed": {
+			errs:       []error{errTest, errTest},
+			errWrapped: errUpdateFailed,
+			errMessage: "update failed: 2 error(s) occurred: test error\ntest error",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			updater := &testOnceUpdater{errs: testCase.errs}
+
+			err := runUpdateOnce(context.Background(), updater)
+
+			assert.Equal(t, 1, updater.calls)
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+				for _, recordErr := range testCase.errs {
+					assert.ErrorIs(t, err, recordErr)
+				}
+			}
+		})
+	}
+}
+
+func Test_main_updateOnce(t *testing.T) {
+	t.Parallel()
+
+	dataDir := lowercaseTempDir(t)
+	backupDir := lowercaseTempDir(t)
+	const mode = 0600
+	err := os.WriteFile(filepath.Join(dataDir, "config.json"),
+		[]byte(`{"settings":[]}`), mode)
+	require.NoError(t, err)
+	env := params.NewFromEnviron([]string{
+		"DATADIR=" + dataDir,
+		"BACKUP_DIRECTORY=" + backupDir,
+		"BACKUP_PERIOD=1h",
+		"HTTP_TIMEOUT=1s",
+	})
+	logger := log.New(log.SetWriters(io.Discard))
+	args := []string{"updater", "update", "--once"}
+
+	// The context is never canceled, so _main only returns
+	// if it does not start the long running goroutines.
+	errCh := make(chan error)
+	go func() {
+		errCh <- _main(context.Background(), env, args, logger,
+			models.BuildInformation{}, time.Now)
+	}()
+
+	const timeout = 10 * time.Second
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-errCh:
+		require.NoError(t, err)
+	case <-timer.C:
+		t.Fatal("update --once did not return after " + timeout.String())
+	}
+
+	entries, err := os.ReadDir(backupDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "backup goroutine should not have run")
+}
+
+// lowercaseTempDir returns a temporary directory removed at the end of
+// the test, since directory paths set in the environment are lowercased.
+func lowercaseTempDir(t *testing.T) (dir string) {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "ddns-updater")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+	if dir != strings.ToLower(dir) {
+		t.Skip("temporary directory path " + dir + " is not lowercase")
+	}
+	return dir
+}
@@ -3,6 +3,7 @@ module github.com/qdm12/ddns-updater
 go 1.20
 
 require (
+	filippo.io/age v1.0.0
 	github.com/breml/rootcerts v0.2.0
 	github.com/containrrr/shoutrrr v0.5.1
 	github.com/go-chi/chi v1.5.4
@@ -13,6 +14,8 @@ require (
 	github.com/qdm12/gosplash v0.1.0
 	github.com/qdm12/log v0.1.0
 	github.com/stretchr/testify v1.7.1
+	go.etcd.io/bbolt v1.3.6
+	golang.org/x/crypto v0.0.0-20220214200702-86341886e292
 	google.golang.org/api v0.96.0
 )
 
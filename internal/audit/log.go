@@ -0,0 +1,190 @@
+// Package audit implements an append-only audit log stored as a
+// file of JSON lines in the data directory, rotated by size.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+)
+
+// FileName is the name of the audit log file in the data directory.
+const FileName = "audit.jsonl"
+
+// RotatedSuffix is the suffix of the previous audit log file,
+// kept when the audit log file is rotated.
+const RotatedSuffix = ".1"
+
+type Log struct {
+	filepath string
+	maxSize  int64
+	mutex    sync.RWMutex
+}
+
+// NewLog creates an audit log in the data directory given. Once the audit
+// log file reaches maxSize bytes, it is renamed with the RotatedSuffix,
+// replacing the previous rotated file, so the audit log uses at most about
+// twice maxSize bytes on disk. A zero maxSize disables the rotation.
+func NewLog(dataDir string, maxSize int64) *Log {
+	return &Log{
+		filepath: filepath.Join(dataDir, FileName),
+		maxSize:  maxSize,
+	}
+}
+
+// Append appends the entries given to the audit log
+// file and syncs the file to disk.
+func (l *Log) Append(entries []models.AuditEntry) (err error) {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	err = l.rotate()
+	if err != nil {
+		return fmt.Errorf("rotating audit log: %w", err)
+	}
+
+	const perms = 0o600
+	file, err := os.OpenFile(l.filepath, os.O_CREATE|os.O_APPEND|os.O_RDWR, perms)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	partial, err := endsWithPartialLine(file)
+	if err != nil {
+		_ = file.Close()
+		return err
+	} else if partial { // terminate a line partially written during a crash
+		_ = writer.WriteByte('\n')
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	for _, entry := range entries {
+		err = encoder.Encode(entry)
+		if err != nil {
+			_ = file.Close()
+			return fmt.Errorf("encoding entry: %w", err)
+		}
+	}
+
+	err = writer.Flush()
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// rotate renames the audit log file with the rotated suffix
+// if it reached the maximum size.
+func (l *Log) rotate() (err error) {
+	if l.maxSize == 0 {
+		return nil
+	}
+	stat, err := os.Stat(l.filepath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if stat.Size() < l.maxSize {
+		return nil
+	}
+	return os.Rename(l.filepath, l.filepath+RotatedSuffix)
+}
+
+// endsWithPartialLine returns true if the file is not empty
+// and does not end with a new line character.
+func endsWithPartialLine(file *os.File) (partial bool, err error) {
+	stat, err := file.Stat()
+	if err != nil {
+		return false, err
+	} else if stat.Size() == 0 {
+		return false, nil
+	}
+	lastByte := make([]byte, 1)
+	_, err = file.ReadAt(lastByte, stat.Size()-1)
+	if err != nil {
+		return false, fmt.Errorf("reading last byte: %w", err)
+	}
+	return lastByte[0] != '\n', nil
+}
+
+// Query returns the entries of the audit log matching the filter,
+// ordered from oldest to newest, including the entries of the rotated
+// audit log file. If the filter has a limit, only the most recent
+// entries are kept in memory while reading. Lines which cannot be
+// decoded, such as a line partially written during a crash, are skipped.
+func (l *Log) Query(filter models.AuditFilter) (entries []models.AuditEntry, err error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	// oldest is the index of the oldest entry once
+	// entries is full and used as a ring buffer.
+	oldest := 0
+	add := func(entry models.AuditEntry) {
+		if filter.Limit == 0 || len(entries) < filter.Limit {
+			entries = append(entries, entry)
+			return
+		}
+		entries[oldest] = entry
+		oldest = (oldest + 1) % filter.Limit
+	}
+
+	for _, path := range []string{l.filepath + RotatedSuffix, l.filepath} {
+		err = scanFile(path, filter, add)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if oldest == 0 {
+		return entries, nil
+	}
+	ordered := make([]models.AuditEntry, 0, len(entries))
+	ordered = append(ordered, entries[oldest:]...)
+	return append(ordered, entries[:oldest]...), nil
+}
+
+// scanFile calls add for each entry of the file at path matching
+// the filter, and does nothing if the file does not exist.
+func scanFile(path string, filter models.AuditFilter,
+	add func(entry models.AuditEntry)) (err error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	const maxLineSize = 1024 * 1024
+	scanner.Buffer(nil, maxLineSize)
+	for scanner.Scan() {
+		var entry models.AuditEntry
+		err = json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil || !filter.Match(entry) {
+			continue
+		}
+		add(entry)
+	}
+	err = scanner.Err()
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	return nil
+}
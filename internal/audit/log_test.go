@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Log(t *testing.T) {
+	t.Parallel()
+
+	dataDir := t.TempDir()
+	log := NewLog(dataDir, 0)
+
+	entries, err := log.Query(models.AuditFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	entryA0 := models.AuditEntry{Time: t0, RecordID: "a", Action: "skip"}
+	entryB0 := models.AuditEntry{Time: t0, RecordID: "b", Action: "update",
+		Status: "failure", ErrorCategory: "authentication", Duration: time.Second}
+	entryA1 := models.AuditEntry{Time: t0.Add(time.Hour), RecordID: "a", Action: "update"}
+	err = log.Append([]models.AuditEntry{entryA0, entryB0})
+	require.NoError(t, err)
+
+	// Simulate a line partially written during a crash
+	file, err := os.OpenFile(filepath.Join(dataDir, FileName), os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"time":"2020-01-01T00:`)
+	require.NoError(t, err)
+	err = file.Close()
+	require.NoError(t, err)
+
+	err = log.Append([]models.AuditEntry{entryA1})
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		filter  models.AuditFilter
+		entries []models.AuditEntry
+	}{
+		"all": {
+			entries: []models.AuditEntry{entryA0, entryB0, entryA1},
+		},
+		"record": {
+			filter:  models.AuditFilter{RecordID: "b"},
+			entries: []models.AuditEntry{entryB0},
+		},
+		"time range": {
+			filter:  models.AuditFilter{From: t0.Add(time.Minute), To: t0.Add(2 * time.Hour)},
+			entries: []models.AuditEntry{entryA1},
+		},
+		"to": {
+			filter:  models.AuditFilter{RecordID: "a", To: t0},
+			entries: []models.AuditEntry{entryA0},
+		},
+		"limit keeps newest": {
+			filter:  models.AuditFilter{Limit: 2},
+			entries: []models.AuditEntry{entryB0, entryA1},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			entries, err := log.Query(testCase.filter)
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.entries, entries)
+		})
+	}
+}
+
+func Test_Log_rotation(t *testing.T) {
+	t.Parallel()
+
+	dataDir := t.TempDir()
+	const maxSize = 1 // rotate before each append
+	log := NewLog(dataDir, maxSize)
+
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := make([]models.AuditEntry, 3)
+	for i := range entries {
+		entries[i] = models.AuditEntry{Time: t0.Add(time.Duration(i) * time.Hour), RecordID: "a"}
+		err := log.Append([]models.AuditEntry{entries[i]})
+		require.NoError(t, err)
+	}
+
+	// the first entry is removed by the second rotation
+	queried, err := log.Query(models.AuditFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, entries[1:], queried)
+
+	queried, err = log.Query(models.AuditFilter{Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, entries[2:], queried)
+
+	_, err = os.Stat(filepath.Join(dataDir, FileName+RotatedSuffix))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,155 @@
+// Package auth authenticates HTTP requests using basic authentication,
+// bearer tokens or a user header set by a trusted reverse proxy.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type Authenticator struct {
+	// basicUsers maps usernames to their bcrypt password hash.
+	basicUsers map[string][]byte
+	// dummyHash is compared against the password of unknown users, so
+	// checking their password takes as long as for existing users.
+	dummyHash []byte
+	// tokens maps bearer tokens to their scope.
+	tokens map[string]Scope
+	// proxyHeader is the header containing the user authenticated by
+	// a reverse proxy, trusted only for requests from proxyNetworks.
+	proxyHeader   string
+	proxyNetworks []netip.Prefix
+}
+
+// New creates an authenticator. Users authenticated with basic
+// authentication or by a trusted reverse proxy are given the
+// write scope, and bearer tokens are given their own scope.
+func New(basicUsers map[string][]byte, tokens map[string]Scope,
+	proxyHeader string, proxyNetworks []netip.Prefix) *Authenticator {
+	return &Authenticator{
+		basicUsers:    basicUsers,
+		dummyHash:     makeDummyHash(basicUsers),
+		tokens:        tokens,
+		proxyHeader:   proxyHeader,
+		proxyNetworks: proxyNetworks,
+	}
+}
+
+var (
+	ErrCredentialsMissing = errors.New("credentials are missing")
+	ErrCredentialsInvalid = errors.New("credentials are invalid")
+)
+
+// Authenticate returns the user and scope of the request, or an error
+// if the request has no credentials or has invalid credentials.
+func (a *Authenticator) Authenticate(request *http.Request) (
+	user string, scope Scope, err error) {
+	user, ok := a.proxyUser(request)
+	if ok {
+		return user, ScopeWrite, nil
+	}
+
+	authorization := request.Header.Get("Authorization")
+	authType, credentials, _ := strings.Cut(authorization, " ")
+	switch {
+	case strings.EqualFold(authType, "Basic") && len(a.basicUsers) > 0:
+		username, password, ok := request.BasicAuth()
+		if !ok || !a.checkPassword(username, password) {
+			return "", "", ErrCredentialsInvalid
+		}
+		return username, ScopeWrite, nil
+	case strings.EqualFold(authType, "Bearer") && len(a.tokens) > 0:
+		scope, ok := a.checkToken(strings.TrimSpace(credentials))
+		if !ok {
+			return "", "", ErrCredentialsInvalid
+		}
+		return "token", scope, nil
+	default:
+		return "", "", ErrCredentialsMissing
+	}
+}
+
+// Challenge returns the value of the WWW-Authenticate header to
+// send with a response to an unauthenticated request.
+func (a *Authenticator) Challenge() (challenge string) {
+	if len(a.basicUsers) > 0 {
+		return `Basic realm="DDNS Updater", charset="UTF-8"`
+	}
+	return `Bearer realm="DDNS Updater"`
+}
+
+// proxyUser returns the user set in the proxy header if the
+// request comes from a trusted network, and false otherwise.
+func (a *Authenticator) proxyUser(request *http.Request) (user string, ok bool) {
+	if a.proxyHeader == "" {
+		return "", false
+	}
+	user = request.Header.Get(a.proxyHeader)
+	if user == "" {
+		return "", false
+	}
+
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return "", false
+	}
+	address, err := netip.ParseAddr(host)
+	if err != nil {
+		return "", false
+	}
+	address = address.Unmap()
+	for _, network := range a.proxyNetworks {
+		if network.Contains(address) {
+			return user, true
+		}
+	}
+	return "", false
+}
+
+func (a *Authenticator) checkPassword(username, password string) (ok bool) {
+	hash, userExists := a.basicUsers[username]
+	if !userExists {
+		hash = a.dummyHash
+	}
+	ok = bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil
+	return ok && userExists
+}
+
+// makeDummyHash returns a bcrypt hash of a random password using the
+// highest cost of the password hashes given, or nil if there is none.
+func makeDummyHash(basicUsers map[string][]byte) (hash []byte) {
+	if len(basicUsers) == 0 {
+		return nil
+	}
+	cost := bcrypt.MinCost
+	for _, userHash := range basicUsers {
+		userCost, err := bcrypt.Cost(userHash)
+		if err == nil && userCost > cost {
+			cost = userCost
+		}
+	}
+	const passwordLength = 16
+	password := make([]byte, passwordLength)
+	_, _ = rand.Read(password)
+	hash, err := bcrypt.GenerateFromPassword(password, cost)
+	if err != nil {
+		panic(err) // cost is within the valid range
+	}
+	return hash
+}
+
+func (a *Authenticator) checkToken(token string) (scope Scope, ok bool) {
+	for validToken, validScope := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(validToken)) == 1 {
+			scope, ok = validScope, true
+		}
+	}
+	return scope, ok
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Scope is an access scope.
+type Scope string
+
+const (
+	// ScopeRead allows reading records, their history and the updater state.
+	ScopeRead Scope = "read"
+	// ScopeWrite allows updating records and importing history,
+	// and includes the read scope.
+	ScopeWrite Scope = "write"
+)
+
+var ErrScopeUnknown = errors.New("scope is unknown")
+
+// ParseScope parses the scope from the string given,
+// which can be read or write and is case insensitive.
+func ParseScope(s string) (scope Scope, err error) {
+	switch scope := Scope(strings.ToLower(s)); scope {
+	case ScopeRead, ScopeWrite:
+		return scope, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrScopeUnknown, s)
+	}
+}
+
+// Allows returns true if the scope grants the required scope.
+func (s Scope) Allows(required Scope) bool {
+	return s == required || s == ScopeWrite
+}
@@ -0,0 +1,275 @@
+package backup
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+)
+
+const (
+	filePrefix = "ddns-updater-backup-"
+	fileSuffix = ".zip"
+)
+
+// Backuper writes zip backups of the database, of the JSON
+// configuration file and of the audit log periodically and on IP address changes,
+// and removes old backups according to its retention settings.
+type Backuper struct {
+	period           time.Duration
+	onChange         bool
+	keep             uint
+	maxAge           time.Duration
+	outputDir        string
+	configPath       string
+	auditPath        string
+	databaseFileName string
+	database         DatabaseWriter
+	recipients       []age.Recipient
+	changes          chan struct{}
+	ziper            *Ziper
+	logger           Logger
+	timeNow          func() time.Time
+}
+
+// NewBackuper creates a backuper writing backups every period if it is not
+// zero, and after each change notified if onChange is true. It keeps the
+// last keep backups if keep is not zero, and the backups younger than maxAge
+// if maxAge is not zero. The configuration file at configPath is not backed
+// up if configPath is empty, and the audit log file at auditPath is backed
+// up only if auditPath is not empty and the file exists. Backups are encrypted to the recipients given,
+// and are not encrypted if there is no recipient.
+func NewBackuper(period time.Duration, onChange bool, keep uint, maxAge time.Duration,
+	outputDir, configPath, auditPath, databaseFileName string, database DatabaseWriter,
+	recipients []age.Recipient, logger Logger, timeNow func() time.Time) *Backuper {
+	return &Backuper{
+		period:           period,
+		onChange:         onChange,
+		keep:             keep,
+		maxAge:           maxAge,
+		outputDir:        outputDir,
+		configPath:       configPath,
+		auditPath:        auditPath,
+		databaseFileName: databaseFileName,
+		database:         database,
+		recipients:       recipients,
+		changes:          make(chan struct{}, 1),
+		ziper:            NewZiper(),
+		logger:           logger,
+		timeNow:          timeNow,
+	}
+}
+
+// NotifyChange triggers a backup if backups on change are enabled.
+// It does not block, and changes notified while a backup is pending
+// are merged into the pending backup.
+func (b *Backuper) NotifyChange() {
+	if !b.onChange {
+		return
+	}
+	select {
+	case b.changes <- struct{}{}:
+	default:
+	}
+}
+
+func (b *Backuper) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+	if b.period == 0 && !b.onChange {
+		b.logger.Info("disabled")
+		return
+	}
+
+	var triggers []string
+	if b.period > 0 {
+		triggers = append(triggers, "each "+b.period.String())
+	}
+	if b.onChange {
+		triggers = append(triggers, "on each IP address change")
+	}
+	encryption := ""
+	if len(b.recipients) > 0 {
+		encryption = "encrypted "
+	}
+	b.logger.Info(strings.Join(triggers, " and ") +
+		"; writing " + encryption + "zip files to directory " + b.outputDir)
+
+	var timer *time.Timer
+	var timerC <-chan time.Time // nil channel if periodic backups are disabled
+	if b.period > 0 {
+		b.backup()
+		timer = time.NewTimer(b.period)
+		defer timer.Stop()
+		timerC = timer.C
+	}
+
+	for {
+		select {
+		case <-timerC:
+			timer.Reset(b.period)
+			b.backup()
+		case <-b.changes:
+			b.backup()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (b *Backuper) backup() {
+	err := b.writeBackup()
+	if err != nil {
+		b.logger.Error(err.Error())
+	}
+	err = b.removeOldBackups()
+	if err != nil {
+		b.logger.Error("removing old backups: " + err.Error())
+	}
+}
+
+// writeBackup writes the zip backup, encrypted if there are recipients,
+// to a temporary file which is then renamed, such that a backup is never
+// partially written.
+func (b *Backuper) writeBackup() (err error) {
+	now := b.timeNow()
+	fileName := filePrefix + strconv.FormatInt(now.UnixNano(), 10) + fileSuffix
+	if len(b.recipients) > 0 {
+		fileName += EncryptedSuffix
+	}
+	tempFile, err := os.CreateTemp(b.outputDir, fileName+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := tempFile.Name()
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tempPath)
+		}
+	}()
+
+	err = b.writeZip(tempFile, now)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("writing backup: %w", err)
+	}
+
+	return os.Rename(tempPath, filepath.Join(b.outputDir, fileName))
+}
+
+func (b *Backuper) writeZip(file io.Writer, now time.Time) (err error) {
+	writer := io.WriteCloser(nopWriteCloser{Writer: file})
+	if len(b.recipients) > 0 {
+		writer, err = age.Encrypt(file, b.recipients...)
+		if err != nil {
+			return fmt.Errorf("encrypting: %w", err)
+		}
+	}
+
+	w := zip.NewWriter(writer)
+	err = b.addDatabase(w, now)
+	if err == nil && b.configPath != "" {
+		err = b.ziper.addFile(w, b.configPath)
+	}
+	if err == nil && b.auditPath != "" {
+		err = b.ziper.addFile(w, b.auditPath)
+		if errors.Is(err, os.ErrNotExist) {
+			err = nil // audit log disabled or not written yet
+		}
+	}
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	// closing the age writer flushes the last encrypted chunk
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func (b *Backuper) addDatabase(w *zip.Writer, now time.Time) (err error) {
+	header := &zip.FileHeader{
+		Name:     b.databaseFileName,
+		Method:   zip.Deflate,
+		Modified: now,
+	}
+	const perms = 0o600
+	header.SetMode(perms)
+	ioWriter, err := w.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = b.database.WriteTo(ioWriter)
+	if err != nil {
+		return fmt.Errorf("writing database: %w", err)
+	}
+	return nil
+}
+
+// removeOldBackups removes the backups exceeding the number
+// of backups to keep or older than the maximum age.
+func (b *Backuper) removeOldBackups() (err error) {
+	if b.keep == 0 && b.maxAge == 0 {
+		return nil
+	}
+
+	dirEntries, err := os.ReadDir(b.outputDir)
+	if err != nil {
+		return err
+	}
+
+	type backupFile struct {
+		name string
+		time time.Time
+	}
+	backups := make([]backupFile, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		timestamp := strings.TrimSuffix(name, EncryptedSuffix)
+		if dirEntry.IsDir() || !strings.HasPrefix(timestamp, filePrefix) ||
+			!strings.HasSuffix(timestamp, fileSuffix) {
+			continue
+		}
+		timestamp = strings.TrimSuffix(strings.TrimPrefix(timestamp, filePrefix), fileSuffix)
+		nanoseconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			continue // not a backup file
+		}
+		backups = append(backups, backupFile{name: name, time: time.Unix(0, nanoseconds)})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].time.After(backups[j].time)
+	})
+
+	now := b.timeNow()
+	for i, backup := range backups {
+		tooMany := b.keep > 0 && uint(i) >= b.keep
+		tooOld := b.maxAge > 0 && now.Sub(backup.time) > b.maxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		err = os.Remove(filepath.Join(b.outputDir, backup.name))
+		if err != nil {
+			return err
+		}
+		b.logger.Info("removed old backup " + backup.name)
+	}
+	return nil
+}
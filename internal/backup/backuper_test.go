@@ -0,0 +1,114 @@
+package backup
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testDatabase struct{}
+
+func (testDatabase) WriteTo(w io.Writer) (n int64, err error) {
+	written, err := io.WriteString(w, `{"records":[]}`)
+	return int64(written), err
+}
+
+type testLogger struct{}
+
+func (testLogger) Info(string)  {}
+func (testLogger) Error(string) {}
+
+func Test_Backuper_backup(t *testing.T) {
+	t.Parallel()
+
+	outputDir := t.TempDir()
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(configPath, []byte(`{"settings":[]}`), 0o600)
+	require.NoError(t, err)
+	auditPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	err = os.WriteFile(auditPath, []byte(`{"record_id":"a"}`+"\n"), 0o600)
+	require.NoError(t, err)
+
+	now := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	backupName := func(t time.Time) string {
+		return filePrefix + strconv.FormatInt(t.UnixNano(), 10) + fileSuffix
+	}
+	oldNames := []string{
+		backupName(now.Add(-3 * time.Hour)),
+		backupName(now.Add(-2 * time.Hour)),
+		backupName(now.Add(-time.Hour)),
+		"ddns-updater-backup-invalid.zip",
+	}
+	for _, name := range oldNames {
+		err = os.WriteFile(filepath.Join(outputDir, name), nil, 0o600)
+		require.NoError(t, err)
+	}
+
+	const keep = 3
+	const maxAge = 150 * time.Minute
+	backuper := NewBackuper(0, false, keep, maxAge, outputDir, configPath,
+		auditPath, "updates.json", testDatabase{}, nil, testLogger{}, func() time.Time { return now })
+
+	backuper.backup()
+
+	dirEntries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	names := make([]string, len(dirEntries))
+	for i, dirEntry := range dirEntries {
+		names[i] = dirEntry.Name()
+	}
+	expectedNames := []string{
+		backupName(now.Add(-2 * time.Hour)),
+		backupName(now.Add(-time.Hour)),
+		backupName(now),
+		"ddns-updater-backup-invalid.zip",
+	}
+	assert.ElementsMatch(t, expectedNames, names)
+
+	extractDir := t.TempDir()
+	extracted, err := Extract(filepath.Join(outputDir, backupName(now)), extractDir,
+		"updates.json", "config.json", "audit.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"updates.json", "config.json", "audit.jsonl"}, extracted)
+	b, err := os.ReadFile(filepath.Join(extractDir, "updates.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"records":[]}`, string(b))
+}
+
+func Test_Backuper_backup_noAuditLog(t *testing.T) {
+	t.Parallel()
+
+	outputDir := t.TempDir()
+	now := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	auditPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	backuper := NewBackuper(0, false, 0, 0, outputDir, "", auditPath,
+		"updates.json", testDatabase{}, nil, testLogger{}, func() time.Time { return now })
+
+	err := backuper.writeBackup()
+	require.NoError(t, err)
+
+	backupPath := filepath.Join(outputDir,
+		filePrefix+strconv.FormatInt(now.UnixNano(), 10)+fileSuffix)
+	extracted, err := Extract(backupPath, t.TempDir(), "updates.json", "audit.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"updates.json"}, extracted)
+}
+
+func Test_Backuper_NotifyChange(t *testing.T) {
+	t.Parallel()
+
+	backuper := NewBackuper(0, true, 0, 0, "", "", "", "", testDatabase{}, nil, testLogger{}, time.Now)
+	backuper.NotifyChange()
+	backuper.NotifyChange() // must not block
+	assert.Len(t, backuper.changes, 1)
+
+	backuper = NewBackuper(0, false, 0, 0, "", "", "", "", testDatabase{}, nil, testLogger{}, time.Now)
+	backuper.NotifyChange()
+	assert.Empty(t, backuper.changes)
+}
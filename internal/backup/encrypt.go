@@ -0,0 +1,128 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"filippo.io/age"
+)
+
+// EncryptedSuffix is the suffix of the encrypted backup file names,
+// added after the zip file extension.
+const EncryptedSuffix = ".age"
+
+// NewRecipients returns the age recipients to encrypt backups to,
+// which is a single scrypt recipient if passphrase is not empty, or
+// the X25519 recipients given otherwise. It returns no recipient if
+// backups are not to be encrypted.
+func NewRecipients(passphrase string, x25519Recipients []*age.X25519Recipient) (
+	recipients []age.Recipient, err error) {
+	if passphrase != "" {
+		recipient, err := age.NewScryptRecipient(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Recipient{recipient}, nil
+	}
+
+	recipients = make([]age.Recipient, len(x25519Recipients))
+	for i, recipient := range x25519Recipients {
+		recipients[i] = recipient
+	}
+	return recipients, nil
+}
+
+// NewIdentities returns the age identities to decrypt backups with,
+// from the passphrase and from the identities file at identityFile,
+// each of them being ignored if empty.
+func NewIdentities(passphrase, identityFile string) (identities []age.Identity, err error) {
+	if passphrase != "" {
+		identity, err := age.NewScryptIdentity(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+
+	if identityFile != "" {
+		file, err := os.Open(identityFile)
+		if err != nil {
+			return nil, err
+		}
+		fileIdentities, err := age.ParseIdentities(file)
+		_ = file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("parsing identities file %s: %w", identityFile, err)
+		}
+		identities = append(identities, fileIdentities...)
+	}
+
+	return identities, nil
+}
+
+// IsEncrypted returns true if the file at path is encrypted with age.
+func IsEncrypted(path string) (encrypted bool, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	header := []byte("age-encryption.org/")
+	b := make([]byte, len(header))
+	_, err = io.ReadFull(file, b)
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	return bytes.Equal(b, header), nil
+}
+
+var (
+	ErrIdentityMissing = errors.New("no identity to decrypt the backup")
+	ErrBackupTooLarge  = errors.New("backup is too large")
+)
+
+// maxBackupSize is the maximum size of a decrypted backup.
+const maxBackupSize = 3 * maxEntrySize
+
+// Decrypt decrypts the age encrypted file at path using the identities
+// given, and writes the decrypted data to a new file at outputPath.
+func Decrypt(path, outputPath string, identities []age.Identity) (err error) {
+	if len(identities) == 0 {
+		return fmt.Errorf("%w", ErrIdentityMissing)
+	}
+
+	input, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	reader, err := age.Decrypt(input, identities...)
+	if err != nil {
+		return fmt.Errorf("decrypting backup: %w", err)
+	}
+
+	const perms = 0o600
+	output, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perms)
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(output, io.LimitReader(reader, maxBackupSize+1))
+	if err != nil {
+		err = fmt.Errorf("decrypting backup: %w", err)
+	} else if n > maxBackupSize {
+		err = fmt.Errorf("%w: more than %d bytes", ErrBackupTooLarge, maxBackupSize)
+	}
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
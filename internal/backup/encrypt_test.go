@@ -0,0 +1,76 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_encryptedBackup(t *testing.T) {
+	t.Parallel()
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	otherIdentity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	recipients, err := NewRecipients("", []*age.X25519Recipient{identity.Recipient()})
+	require.NoError(t, err)
+
+	outputDir := t.TempDir()
+	now := time.Unix(0, 1)
+	backuper := NewBackuper(0, false, 0, 0, outputDir, "", "", "updates.json",
+		testDatabase{}, recipients, testLogger{}, func() time.Time { return now })
+
+	err = backuper.writeBackup()
+	require.NoError(t, err)
+
+	path := filepath.Join(outputDir, "ddns-updater-backup-1.zip.age")
+	encrypted, err := IsEncrypted(path)
+	require.NoError(t, err)
+	assert.True(t, encrypted)
+
+	err = Decrypt(path, filepath.Join(t.TempDir(), "backup.zip"),
+		[]age.Identity{otherIdentity})
+	assert.ErrorContains(t, err, "decrypting backup: no identity matched any of the recipients")
+
+	decryptedPath := filepath.Join(t.TempDir(), "backup.zip")
+	err = Decrypt(path, decryptedPath, []age.Identity{otherIdentity, identity})
+	require.NoError(t, err)
+	encrypted, err = IsEncrypted(decryptedPath)
+	require.NoError(t, err)
+	assert.False(t, encrypted)
+
+	extractDir := t.TempDir()
+	names, err := Extract(decryptedPath, extractDir, "updates.json")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"updates.json"}, names)
+	b, err := os.ReadFile(filepath.Join(extractDir, "updates.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"records":[]}`, string(b))
+}
+
+func Test_NewIdentities(t *testing.T) {
+	t.Parallel()
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	identityFile := filepath.Join(t.TempDir(), "key.txt")
+	content := "# created: 2020-01-01T00:00:00Z\n" + identity.String() + "\n"
+	err = os.WriteFile(identityFile, []byte(content), 0o600)
+	require.NoError(t, err)
+
+	identities, err := NewIdentities("passphrase", identityFile)
+	require.NoError(t, err)
+	require.Len(t, identities, 2)
+	assert.IsType(t, &age.ScryptIdentity{}, identities[0])
+	assert.Equal(t, identity, identities[1])
+
+	identities, err = NewIdentities("", "")
+	require.NoError(t, err)
+	assert.Empty(t, identities)
+}
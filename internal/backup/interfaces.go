@@ -0,0 +1,12 @@
+package backup
+
+import "io"
+
+type DatabaseWriter interface {
+	WriteTo(w io.Writer) (n int64, err error)
+}
+
+type Logger interface {
+	Info(s string)
+	Error(s string)
+}
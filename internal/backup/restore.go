@@ -0,0 +1,150 @@
+package backup
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+var (
+	ErrEntryNotAllowed = errors.New("archive entry is not allowed")
+	ErrEntryDuplicated = errors.New("archive entry is duplicated")
+	ErrEntryTooLarge   = errors.New("archive entry is too large")
+)
+
+// maxEntrySize is the maximum uncompressed size of a file
+// extracted from a backup, to protect against zip bombs.
+const maxEntrySize = 1 << 30
+
+// Extract extracts the files of the zip archive at zipPath to the
+// directory outputDir, and returns the names of the files extracted.
+// It returns an error if the archive contains an entry with a name
+// not in allowedNames.
+func Extract(zipPath, outputDir string, allowedNames ...string) (names []string, err error) {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	allowed := make(map[string]struct{}, len(allowedNames))
+	for _, name := range allowedNames {
+		allowed[name] = struct{}{}
+	}
+
+	extracted := make(map[string]struct{}, len(reader.File))
+	for _, file := range reader.File {
+		name := file.Name
+		if _, ok := allowed[name]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrEntryNotAllowed, name)
+		} else if _, ok := extracted[name]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrEntryDuplicated, name)
+		}
+
+		err = extractFile(file, filepath.Join(outputDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("extracting %s: %w", name, err)
+		}
+		extracted[name] = struct{}{}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func extractFile(file *zip.File, path string) (err error) {
+	if file.UncompressedSize64 > maxEntrySize {
+		return fmt.Errorf("%w: %d bytes", ErrEntryTooLarge, file.UncompressedSize64)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	const perms = 0o600
+	output, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perms)
+	if err != nil {
+		return err
+	}
+
+	// the uncompressed size of the header can be forged
+	n, err := io.Copy(output, io.LimitReader(reader, maxEntrySize+1))
+	if err == nil && n > maxEntrySize {
+		err = fmt.Errorf("%w: more than %d bytes", ErrEntryTooLarge, maxEntrySize)
+	}
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// BeforeRestoreSuffix is the suffix added to the path of files
+// replaced by Replace to keep a copy of them.
+const BeforeRestoreSuffix = ".before-restore"
+
+// Replace moves each source file path to its destination file path
+// of the map given. Existing destination files are copied to their
+// path suffixed with BeforeRestoreSuffix before being replaced.
+func Replace(sourceToDestination map[string]string) (err error) {
+	sources := make([]string, 0, len(sourceToDestination))
+	for source := range sourceToDestination {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		destination := sourceToDestination[source]
+		err = copyFile(destination, destination+BeforeRestoreSuffix)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("saving %s: %w", destination, err)
+		}
+
+		err = moveFile(source, destination)
+		if err != nil {
+			return fmt.Errorf("replacing %s: %w", destination, err)
+		}
+	}
+	return nil
+}
+
+func moveFile(source, destination string) (err error) {
+	err = os.Rename(source, destination)
+	if err == nil {
+		return nil
+	}
+	// renaming fails across file systems or for a
+	// bind mounted file, so copy the file instead.
+	err = copyFile(source, destination)
+	if err != nil {
+		return err
+	}
+	return os.Remove(source)
+}
+
+func copyFile(source, destination string) (err error) {
+	input, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	const perms = 0o600
+	output, err := os.OpenFile(destination, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perms)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(output, input)
+	if err == nil {
+		err = output.Sync()
+	}
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+package backup
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Extract(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		entries    []string
+		names      []string
+		errWrapped error
+		errMessage string
+	}{
+		"allowed entries": {
+			entries: []string{"updates.db", "config.json"},
+			names:   []string{"updates.db", "config.json"},
+		},
+		"entry not allowed": {
+			entries:    []string{"updates.db", "../config.json"},
+			errWrapped: ErrEntryNotAllowed,
+			errMessage: "archive entry is not allowed: ../config.json",
+		},
+		"duplicated entry": {
+			entries:    []string{"updates.db", "updates.db"},
+			errWrapped: ErrEntryDuplicated,
+			errMessage: "archive entry is duplicated: updates.db",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			zipPath := filepath.Join(t.TempDir(), "backup.zip")
+			file, err := os.Create(zipPath)
+			require.NoError(t, err)
+			writer := zip.NewWriter(file)
+			for _, entry := range testCase.entries {
+				entryWriter, err := writer.Create(entry)
+				require.NoError(t, err)
+				_, err = entryWriter.Write([]byte(entry))
+				require.NoError(t, err)
+			}
+			require.NoError(t, writer.Close())
+			require.NoError(t, file.Close())
+
+			outputDir := t.TempDir()
+			names, err := Extract(zipPath, outputDir, "updates.db", "config.json")
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+				return
+			}
+			assert.Equal(t, testCase.names, names)
+			for _, name := range names {
+				b, err := os.ReadFile(filepath.Join(outputDir, name))
+				require.NoError(t, err)
+				assert.Equal(t, name, string(b))
+			}
+		})
+	}
+}
+
+func Test_Replace(t *testing.T) {
+	t.Parallel()
+
+	sourceDir := t.TempDir()
+	destinationDir := t.TempDir()
+	files := map[string]string{
+		"existing": "new existing",
+		"new":      "new",
+	}
+	sourceToDestination := make(map[string]string, len(files))
+	for name, content := range files {
+		source := filepath.Join(sourceDir, name)
+		err := os.WriteFile(source, []byte(content), 0o600)
+		require.NoError(t, err)
+		sourceToDestination[source] = filepath.Join(destinationDir, name)
+	}
+	err := os.WriteFile(filepath.Join(destinationDir, "existing"), []byte("old existing"), 0o600)
+	require.NoError(t, err)
+
+	err = Replace(sourceToDestination)
+	require.NoError(t, err)
+
+	expectedFiles := map[string]string{
+		"existing":                       "new existing",
+		"existing" + BeforeRestoreSuffix: "old existing",
+		"new":                            "new",
+	}
+	dirEntries, err := os.ReadDir(destinationDir)
+	require.NoError(t, err)
+	require.Len(t, dirEntries, len(expectedFiles))
+	for name, expectedContent := range expectedFiles {
+		b, err := os.ReadFile(filepath.Join(destinationDir, name))
+		require.NoError(t, err)
+		assert.Equal(t, expectedContent, string(b))
+	}
+	dirEntries, err = os.ReadDir(sourceDir)
+	require.NoError(t, err)
+	assert.Empty(t, dirEntries)
+}
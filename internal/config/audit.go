@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/qdm12/golibs/params"
+)
+
+type Audit struct {
+	// Enabled is true to record each decision taken for
+	// each record in the audit log file.
+	Enabled bool
+	// MaxSize is the size in bytes at which the audit log file
+	// is rotated, and zero means the file is never rotated.
+	MaxSize int64
+}
+
+func (a *Audit) get(env params.Interface) (err error) {
+	a.Enabled, err = env.YesNo("AUDIT_LOG", params.Default("yes"))
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable AUDIT_LOG", err)
+	}
+
+	const maxSizeMB = 1024 * 1024
+	sizeMB, err := env.IntRange("AUDIT_LOG_MAX_SIZE_MB", 0, maxSizeMB, params.Default("10"))
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable AUDIT_LOG_MAX_SIZE_MB", err)
+	}
+	const bytesPerMB = 1024 * 1024
+	a.MaxSize = int64(sizeMB) * bytesPerMB
+	return nil
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/qdm12/ddns-updater/internal/auth"
+	"github.com/qdm12/golibs/params"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type Auth struct {
+	// BasicUsers maps usernames to their bcrypt password hash.
+	BasicUsers map[string][]byte
+	// Tokens maps bearer tokens to their scope.
+	Tokens map[string]auth.Scope
+	// ProxyHeader is the header containing the user authenticated
+	// by a reverse proxy, and is empty to not trust any proxy.
+	ProxyHeader string
+	// ProxyNetworks are the networks of the reverse proxies
+	// trusted to set the proxy header.
+	ProxyNetworks []netip.Prefix
+}
+
+// Enabled returns true if at least one authentication method is set.
+func (a *Auth) Enabled() bool {
+	return len(a.BasicUsers) > 0 || len(a.Tokens) > 0 || a.ProxyHeader != ""
+}
+
+var (
+	ErrBasicUserMalformed = errors.New("basic user is malformed")
+	ErrTokenMalformed     = errors.New("token is malformed")
+	ErrTokenTooShort      = errors.New("token is too short")
+	ErrProxyNetworks      = errors.New("proxy header and proxy networks must be set together")
+)
+
+func (a *Auth) get(env params.Interface) (err error) {
+	basicUsers, err := env.CSV("AUTH_BASIC_USERS", params.CaseSensitiveValue())
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable AUTH_BASIC_USERS", err)
+	}
+	a.BasicUsers, err = parseBasicUsers(basicUsers)
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable AUTH_BASIC_USERS", err)
+	}
+
+	tokens, err := env.CSV("AUTH_TOKENS", params.CaseSensitiveValue(), params.Unset())
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable AUTH_TOKENS", err)
+	}
+	a.Tokens, err = parseTokens(tokens)
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable AUTH_TOKENS", err)
+	}
+
+	a.ProxyHeader, err = env.Get("AUTH_PROXY_HEADER")
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable AUTH_PROXY_HEADER", err)
+	}
+	a.ProxyHeader = http.CanonicalHeaderKey(a.ProxyHeader)
+
+	proxyNetworks, err := env.CSV("AUTH_PROXY_NETWORKS")
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable AUTH_PROXY_NETWORKS", err)
+	}
+	a.ProxyNetworks = make([]netip.Prefix, len(proxyNetworks))
+	for i, network := range proxyNetworks {
+		a.ProxyNetworks[i], err = netip.ParsePrefix(strings.TrimSpace(network))
+		if err != nil {
+			return fmt.Errorf("%w: for environment variable AUTH_PROXY_NETWORKS", err)
+		}
+	}
+
+	if (a.ProxyHeader == "") != (len(a.ProxyNetworks) == 0) {
+		return fmt.Errorf("%w: for environment variables AUTH_PROXY_HEADER and AUTH_PROXY_NETWORKS",
+			ErrProxyNetworks)
+	}
+
+	return nil
+}
+
+// parseBasicUsers parses basic users in the format username:bcrypthash.
+func parseBasicUsers(entries []string) (users map[string][]byte, err error) {
+	users = make(map[string][]byte, len(entries))
+	for _, entry := range entries {
+		username, hash, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || username == "" {
+			return nil, fmt.Errorf("%w: expected username:bcrypthash", ErrBasicUserMalformed)
+		}
+		_, err = bcrypt.Cost([]byte(hash))
+		if err != nil {
+			return nil, fmt.Errorf("%w: for user %s: %w", ErrBasicUserMalformed, username, err)
+		}
+		users[username] = []byte(hash)
+	}
+	return users, nil
+}
+
+// parseTokens parses bearer tokens in the format scope:token.
+func parseTokens(entries []string) (tokens map[string]auth.Scope, err error) {
+	const minTokenLength = 16
+	tokens = make(map[string]auth.Scope, len(entries))
+	for i, entry := range entries {
+		scopeString, token, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: token %d: expected scope:token", ErrTokenMalformed, i+1)
+		}
+		scope, err := auth.ParseScope(scopeString)
+		if err != nil {
+			return nil, fmt.Errorf("token %d: %w", i+1, err)
+		}
+		if len(token) < minTokenLength {
+			return nil, fmt.Errorf("%w: token %d has %d characters, minimum is %d",
+				ErrTokenTooShort, i+1, len(token), minTokenLength)
+		}
+		tokens[token] = scope
+	}
+	return tokens, nil
+}
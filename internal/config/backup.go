@@ -1,17 +1,41 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"filippo.io/age"
 	"github.com/qdm12/golibs/params"
 )
 
 type Backup struct {
 	Period    time.Duration
 	Directory string
+	// OnChange is true to write a backup each
+	// time an IP address change is persisted.
+	OnChange bool
+	// Keep is the number of most recent backups to keep,
+	// and zero means all backups are kept.
+	Keep uint
+	// MaxAge is the maximum age of backups to keep,
+	// and zero means no maximum.
+	MaxAge time.Duration
+	// Passphrase is the passphrase to encrypt and decrypt backups
+	// with, and is empty if backups are not passphrase encrypted.
+	Passphrase string
+	// Recipients are the age public keys to encrypt backups to,
+	// and cannot be set together with Passphrase.
+	Recipients []*age.X25519Recipient
+	// IdentityFile is the path to the age identities file to
+	// decrypt backups with on restore, and can be empty.
+	IdentityFile string
 }
 
+var ErrBackupEncryptionConflict = errors.New(
+	"backup passphrase and recipients cannot be both set")
+
 func (b *Backup) get(env params.Interface) (err error) {
 	b.Period, err = env.Duration("BACKUP_PERIOD", params.Default("0"))
 	if err != nil {
@@ -23,5 +47,49 @@ func (b *Backup) get(env params.Interface) (err error) {
 		return fmt.Errorf("%w: for environment variable BACKUP_DIRECTORY", err)
 	}
 
+	b.OnChange, err = env.YesNo("BACKUP_ON_CHANGE", params.Default("no"))
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable BACKUP_ON_CHANGE", err)
+	}
+
+	const maxKeep = 100000
+	keep, err := env.IntRange("BACKUP_KEEP", 0, maxKeep, params.Default("0"))
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable BACKUP_KEEP", err)
+	}
+	b.Keep = uint(keep)
+
+	b.MaxAge, err = env.Duration("BACKUP_MAX_AGE", params.Default("0"))
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable BACKUP_MAX_AGE", err)
+	}
+
+	b.Passphrase, err = env.Get("BACKUP_PASSPHRASE", params.CaseSensitiveValue(), params.Unset())
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable BACKUP_PASSPHRASE", err)
+	}
+
+	recipients, err := env.CSV("BACKUP_RECIPIENTS", params.CaseSensitiveValue())
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable BACKUP_RECIPIENTS", err)
+	}
+	b.Recipients = make([]*age.X25519Recipient, len(recipients))
+	for i, recipient := range recipients {
+		b.Recipients[i], err = age.ParseX25519Recipient(strings.TrimSpace(recipient))
+		if err != nil {
+			return fmt.Errorf("%w: for environment variable BACKUP_RECIPIENTS", err)
+		}
+	}
+
+	if b.Passphrase != "" && len(b.Recipients) > 0 {
+		return fmt.Errorf("%w: for environment variables BACKUP_PASSPHRASE and BACKUP_RECIPIENTS",
+			ErrBackupEncryptionConflict)
+	}
+
+	b.IdentityFile, err = env.Get("BACKUP_IDENTITY_FILE", params.CaseSensitiveValue())
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable BACKUP_IDENTITY_FILE", err)
+	}
+
 	return nil
 }
@@ -14,8 +14,12 @@ type Config struct {
 	Resolver resolver.Settings
 	IPv6     IPv6
 	Server   Server
+	Auth     Auth
 	Health   Health
 	Paths    Paths
+	Database Database
+	Audit    Audit
+	Reload   Reload
 	Backup   Backup
 	Logger   Logger
 	Shoutrrr Shoutrrr
@@ -55,6 +59,11 @@ func (c *Config) Get(env params.Interface) (warnings []string, err error) {
 		return warnings, err
 	}
 
+	err = c.Auth.get(env)
+	if err != nil {
+		return warnings, err
+	}
+
 	warning, err = c.Health.Get(env)
 	warnings = appendIfNotEmpty(warnings, warning)
 	if err != nil {
@@ -66,6 +75,21 @@ func (c *Config) Get(env params.Interface) (warnings []string, err error) {
 		return warnings, err
 	}
 
+	err = c.Database.get(env)
+	if err != nil {
+		return warnings, err
+	}
+
+	err = c.Audit.get(env)
+	if err != nil {
+		return warnings, err
+	}
+
+	err = c.Reload.get(env)
+	if err != nil {
+		return warnings, err
+	}
+
 	err = c.Backup.get(env)
 	if err != nil {
 		return warnings, err
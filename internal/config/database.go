@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/qdm12/golibs/params"
+)
+
+const (
+	DatabaseJSON = "json"
+	DatabaseBolt = "bolt"
+)
+
+type Database struct {
+	// Backend is the persistent database backend,
+	// and can be DatabaseJSON or DatabaseBolt.
+	Backend string
+	// Generations is the number of previous generations
+	// of the JSON database file to keep.
+	Generations uint
+	// Recovery is true to recover the JSON database file from its
+	// newest valid generation if it is invalid at startup.
+	Recovery bool
+	// Retention is the retention policy of the records history.
+	Retention models.Retention
+}
+
+func (d *Database) get(env params.Interface) (err error) {
+	d.Backend, err = env.Inside("DATABASE_BACKEND",
+		[]string{DatabaseJSON, DatabaseBolt}, params.Default(DatabaseJSON))
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable DATABASE_BACKEND", err)
+	}
+
+	const maxGenerations = 100
+	generations, err := env.IntRange("DATABASE_GENERATIONS", 0, maxGenerations, params.Default("3"))
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable DATABASE_GENERATIONS", err)
+	}
+	d.Generations = uint(generations)
+
+	d.Recovery, err = env.YesNo("DATABASE_RECOVERY", params.Default("yes"))
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable DATABASE_RECOVERY", err)
+	}
+
+	return d.getRetention(env)
+}
+
+func (d *Database) getRetention(env params.Interface) (err error) {
+	const maxEvents = 1000000
+	events, err := env.IntRange("HISTORY_MAX_EVENTS", 0, maxEvents, params.Default("0"))
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable HISTORY_MAX_EVENTS", err)
+	}
+	d.Retention.MaxEvents = uint(events)
+
+	d.Retention.MaxAge, err = env.Duration("HISTORY_MAX_AGE", params.Default("0"))
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable HISTORY_MAX_AGE", err)
+	}
+
+	d.Retention.DownsampleAge, err = env.Duration("HISTORY_DOWNSAMPLE_AGE", params.Default("0"))
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable HISTORY_DOWNSAMPLE_AGE", err)
+	}
+
+	d.Retention.DownsamplePeriod, err = env.Duration("HISTORY_DOWNSAMPLE_PERIOD", params.Default("24h"))
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable HISTORY_DOWNSAMPLE_PERIOD", err)
+	}
+
+	return nil
+}
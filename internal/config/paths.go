@@ -10,6 +10,9 @@ import (
 type Paths struct {
 	DataDir string
 	JSON    string // obtained from DataDir
+	// JSONFromEnv is true if the JSON configuration is set with
+	// the environment variable CONFIG instead of the JSON file.
+	JSONFromEnv bool
 }
 
 func (p *Paths) get(env params.Interface) (err error) {
@@ -19,5 +22,11 @@ func (p *Paths) get(env params.Interface) (err error) {
 	}
 
 	p.JSON = filepath.Join(p.DataDir, "config.json")
+
+	jsonConfig, err := env.Get("CONFIG", params.CaseSensitiveValue())
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable CONFIG", err)
+	}
+	p.JSONFromEnv = jsonConfig != ""
 	return nil
 }
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/qdm12/golibs/params"
+)
+
+type Reload struct {
+	// WatchPeriod is the period to check the JSON configuration
+	// file for changes, and is zero to disable checking it.
+	WatchPeriod time.Duration
+}
+
+func (r *Reload) get(env params.Interface) (err error) {
+	r.WatchPeriod, err = env.Duration("CONFIG_WATCH_PERIOD", params.Default("5s"))
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable CONFIG_WATCH_PERIOD", err)
+	}
+	return nil
+}
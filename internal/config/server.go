@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/qdm12/golibs/params"
@@ -9,6 +10,24 @@ import (
 type Server struct {
 	Port    uint16
 	RootURL string
+	TLS     TLS
+}
+
+// TLS contains the TLS settings of the server.
+type TLS struct {
+	// CertFile and KeyFile are the paths to the PEM encoded certificate
+	// and private key files, and are empty to serve plain HTTP.
+	CertFile string
+	KeyFile  string
+	// ClientCAFile is the path to the PEM encoded certificate authorities
+	// bundle to verify client certificates against for the API, and is
+	// empty to not verify client certificates.
+	ClientCAFile string
+}
+
+// Enabled returns true if the server should serve HTTPS.
+func (t *TLS) Enabled() bool {
+	return t.CertFile != ""
 }
 
 func (s *Server) get(env params.Interface) (warning string, err error) {
@@ -22,5 +41,40 @@ func (s *Server) get(env params.Interface) (warning string, err error) {
 		return "", fmt.Errorf("%w: for environment variable LISTENING_PORT", err)
 	}
 
+	err = s.TLS.get(env)
 	return warning, err
 }
+
+var (
+	ErrTLSKeyPairIncomplete  = errors.New("TLS certificate and key files must be set together")
+	ErrTLSClientCAWithoutTLS = errors.New("TLS client CA file requires the TLS certificate and key files")
+)
+
+func (t *TLS) get(env params.Interface) (err error) {
+	t.CertFile, err = env.Get("SERVER_TLS_CERT_FILE", params.CaseSensitiveValue())
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable SERVER_TLS_CERT_FILE", err)
+	}
+
+	t.KeyFile, err = env.Get("SERVER_TLS_KEY_FILE", params.CaseSensitiveValue())
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable SERVER_TLS_KEY_FILE", err)
+	}
+
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return fmt.Errorf("%w: for environment variables SERVER_TLS_CERT_FILE and SERVER_TLS_KEY_FILE",
+			ErrTLSKeyPairIncomplete)
+	}
+
+	t.ClientCAFile, err = env.Get("SERVER_TLS_CLIENT_CA_FILE", params.CaseSensitiveValue())
+	if err != nil {
+		return fmt.Errorf("%w: for environment variable SERVER_TLS_CLIENT_CA_FILE", err)
+	}
+
+	if t.ClientCAFile != "" && !t.Enabled() {
+		return fmt.Errorf("%w: for environment variable SERVER_TLS_CLIENT_CA_FILE",
+			ErrTLSClientCAWithoutTLS)
+	}
+
+	return nil
+}
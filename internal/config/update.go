@@ -1,16 +1,42 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
+	"github.com/qdm12/ddns-updater/internal/cron"
 	"github.com/qdm12/golibs/params"
 )
 
+var (
+	ErrPeriodNotPositive = errors.New("period must be positive")
+	ErrPeriodNotValid    = errors.New("period is not valid")
+)
+
 type Update struct {
-	Period   time.Duration
-	Cooldown time.Duration
+	// Period is the period to check records, and is zero
+	// if Cron is set instead.
+	Period time.Duration
+	// Cron is the cron schedule to check records, and is nil
+	// if Period is set instead.
+	Cron *cron.Schedule
+	// Jitter is the maximum random delay added to each
+	// scheduled check of the records.
+	Jitter       time.Duration
+	Cooldown     time.Duration
+	RetryInitial time.Duration
+	RetryMaximum time.Duration
+	// Workers is the maximum number of records checked
+	// and updated concurrently.
+	Workers uint
+	// ProviderWorkers is the maximum number of records of
+	// the same provider checked and updated concurrently.
+	ProviderWorkers uint
+	// DryRun is true to only log the updates that would be
+	// done, without updating any record.
+	DryRun bool
 }
 
 func (u *Update) get(env params.Interface) (warning string, err error) {
@@ -19,11 +45,44 @@ func (u *Update) get(env params.Interface) (warning string, err error) {
 		return warning, err
 	}
 
+	u.Jitter, err = env.Duration("PERIOD_JITTER", params.Default("0"))
+	if err != nil {
+		return "", fmt.Errorf("%w: for environment variable PERIOD_JITTER", err)
+	}
+
 	u.Cooldown, err = env.Duration("UPDATE_COOLDOWN_PERIOD", params.Default("5m"))
 	if err != nil {
 		return "", fmt.Errorf("%w: for environment variable UPDATE_COOLDOWN_PERIOD", err)
 	}
 
+	u.RetryInitial, err = env.Duration("RETRY_BACKOFF_INITIAL", params.Default("1m"))
+	if err != nil {
+		return "", fmt.Errorf("%w: for environment variable RETRY_BACKOFF_INITIAL", err)
+	}
+
+	u.RetryMaximum, err = env.Duration("RETRY_BACKOFF_MAXIMUM", params.Default("1h"))
+	if err != nil {
+		return "", fmt.Errorf("%w: for environment variable RETRY_BACKOFF_MAXIMUM", err)
+	}
+
+	const maxWorkers = 256
+	workers, err := env.IntRange("UPDATE_WORKERS", 1, maxWorkers, params.Default("4"))
+	if err != nil {
+		return "", fmt.Errorf("%w: for environment variable UPDATE_WORKERS", err)
+	}
+	u.Workers = uint(workers)
+
+	providerWorkers, err := env.IntRange("UPDATE_PROVIDER_WORKERS", 1, maxWorkers, params.Default("1"))
+	if err != nil {
+		return "", fmt.Errorf("%w: for environment variable UPDATE_PROVIDER_WORKERS", err)
+	}
+	u.ProviderWorkers = uint(providerWorkers)
+
+	u.DryRun, err = env.YesNo("DRY_RUN", params.Default("no"))
+	if err != nil {
+		return "", fmt.Errorf("%w: for environment variable DRY_RUN", err)
+	}
+
 	return warning, nil
 }
 
@@ -46,10 +105,24 @@ func (u *Update) getPeriod(env params.Interface) (warning string, err error) {
 		}
 	}
 
-	u.Period, err = env.Duration("PERIOD", params.Default("10m"))
+	s, err = env.Get("PERIOD", params.Default("10m"))
 	if err != nil {
 		return "", fmt.Errorf("%w: for environment variable PERIOD", err)
 	}
 
-	return "", err
+	u.Period, err = time.ParseDuration(s)
+	if err == nil {
+		if u.Period <= 0 {
+			return "", fmt.Errorf("%w: %s for environment variable PERIOD", ErrPeriodNotPositive, u.Period)
+		}
+		return "", nil
+	}
+
+	u.Cron, err = cron.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q is neither a duration nor a cron expression (%w) "+
+			"for environment variable PERIOD", ErrPeriodNotValid, s, err)
+	}
+
+	return "", nil
 }
@@ -0,0 +1,222 @@
+// Package cron parses standard 5-field cron expressions
+// and computes their activation times.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	expression string
+	minutes    uint64
+	hours      uint64
+	daysOfMon  uint64
+	months     uint64
+	daysOfWeek uint64
+	// domStar and dowStar are true if the day of month and day
+	// of week fields start with a star, respectively, such as
+	// "*" or "*/2", in which case a day must match both fields.
+	domStar bool
+	dowStar bool
+}
+
+type field struct {
+	name    string
+	minimum uint
+	maximum uint
+	names   map[string]uint
+}
+
+//nolint:gochecknoglobals
+var (
+	minuteField = field{name: "minute", minimum: 0, maximum: 59}
+	hourField   = field{name: "hour", minimum: 0, maximum: 23}
+	domField    = field{name: "day of month", minimum: 1, maximum: 31}
+	monthField  = field{name: "month", minimum: 1, maximum: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = field{name: "day of week", minimum: 0, maximum: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+	macros = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+var (
+	ErrFieldsCount  = errors.New("cron expression must have 5 fields")
+	ErrValueInvalid = errors.New("value is not valid")
+	ErrOutOfRange   = errors.New("value is out of range")
+	ErrStepInvalid  = errors.New("step is not valid")
+)
+
+// Parse parses a standard 5-field cron expression in the format
+// "minute hour day-of-month month day-of-week". Each field supports
+// wildcards, ranges, lists and steps such as "*/15" or "1-5,10".
+// Macros such as "@hourly" or "@daily" are also supported.
+func Parse(expression string) (schedule *Schedule, err error) {
+	fields := strings.Fields(expression)
+	if len(fields) == 1 {
+		if macro, ok := macros[strings.ToLower(fields[0])]; ok {
+			fields = strings.Fields(macro)
+		}
+	}
+
+	const expectedFields = 5
+	if len(fields) != expectedFields {
+		return nil, fmt.Errorf("%w: %q has %d fields",
+			ErrFieldsCount, expression, len(fields))
+	}
+
+	schedule = &Schedule{
+		expression: expression,
+		domStar:    strings.HasPrefix(fields[2], "*"),
+		dowStar:    strings.HasPrefix(fields[4], "*"),
+	}
+
+	for i, target := range []struct {
+		field field
+		bits  *uint64
+	}{
+		{field: minuteField, bits: &schedule.minutes},
+		{field: hourField, bits: &schedule.hours},
+		{field: domField, bits: &schedule.daysOfMon},
+		{field: monthField, bits: &schedule.months},
+		{field: dowField, bits: &schedule.daysOfWeek},
+	} {
+		*target.bits, err = parseField(fields[i], target.field)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s field: %w", target.field.name, err)
+		}
+	}
+
+	// Day of week 7 is Sunday, like 0.
+	const sunday7 = 1 << 7
+	if schedule.daysOfWeek&sunday7 != 0 {
+		schedule.daysOfWeek = schedule.daysOfWeek&^sunday7 | 1
+	}
+
+	return schedule, nil
+}
+
+func parseField(s string, f field) (bits uint64, err error) {
+	for _, part := range strings.Split(s, ",") {
+		partBits, err := parsePart(part, f)
+		if err != nil {
+			return 0, err
+		}
+		bits |= partBits
+	}
+	return bits, nil
+}
+
+func parsePart(part string, f field) (bits uint64, err error) {
+	rangePart, stepPart, hasStep := strings.Cut(part, "/")
+	step := uint(1)
+	if hasStep {
+		stepInt, err := strconv.Atoi(stepPart)
+		if err != nil || stepInt <= 0 {
+			return 0, fmt.Errorf("%w: %q", ErrStepInvalid, stepPart)
+		}
+		step = uint(stepInt)
+	}
+
+	var start, end uint
+	switch {
+	case rangePart == "*":
+		start, end = f.minimum, f.maximum
+	case strings.Contains(rangePart, "-"):
+		startPart, endPart, _ := strings.Cut(rangePart, "-")
+		start, err = parseValue(startPart, f)
+		if err != nil {
+			return 0, err
+		}
+		end, err = parseValue(endPart, f)
+		if err != nil {
+			return 0, err
+		}
+		if end < start {
+			return 0, fmt.Errorf("%w: range %q is reversed", ErrValueInvalid, rangePart)
+		}
+	default:
+		start, err = parseValue(rangePart, f)
+		if err != nil {
+			return 0, err
+		}
+		end = start
+		if hasStep { // i.e. 5/15 is the same as 5-max/15
+			end = f.maximum
+		}
+	}
+
+	for value := start; value <= end; value += step {
+		bits |= 1 << value
+	}
+	return bits, nil
+}
+
+func parseValue(s string, f field) (value uint, err error) {
+	if namedValue, ok := f.names[strings.ToLower(s)]; ok {
+		return namedValue, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrValueInvalid, s)
+	} else if n < int(f.minimum) || n > int(f.maximum) {
+		return 0, fmt.Errorf("%w: %d must be between %d and %d",
+			ErrOutOfRange, n, f.minimum, f.maximum)
+	}
+	return uint(n), nil
+}
+
+// Next returns the first activation time strictly after the time given,
+// in the location of the time given. It returns the zero time if no
+// activation time can be found within the next five years, which can
+// only happen for expressions such as "0 0 30 2 *".
+func (s *Schedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	const maxYears = 5
+	yearLimit := t.Year() + maxYears
+
+	for t.Year() <= yearLimit {
+		switch {
+		case s.months&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hours&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minutes&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.daysOfMon&(1<<uint(t.Day())) != 0
+	dowMatch := s.daysOfWeek&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func (s *Schedule) String() string {
+	return s.expression
+}
@@ -0,0 +1,142 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Parse(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		expression string
+		errMessage string
+	}{
+		"every minute": {
+			expression: "* * * * *",
+		},
+		"complex": {
+			expression: "*/15 8-18 * jan-jun mon-fri",
+		},
+		"macro": {
+			expression: "@hourly",
+		},
+		"too few fields": {
+			expression: "* * * *",
+			errMessage: `cron expression must have 5 fields: "* * * *" has 4 fields`,
+		},
+		"out of range": {
+			expression: "60 * * * *",
+			errMessage: "parsing minute field: value is out of range: 60 must be between 0 and 59",
+		},
+		"bad step": {
+			expression: "*/0 * * * *",
+			errMessage: `parsing minute field: step is not valid: "0"`,
+		},
+		"reversed range": {
+			expression: "* 5-3 * * *",
+			errMessage: `parsing hour field: value is not valid: range "5-3" is reversed`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			schedule, err := Parse(testCase.expression)
+
+			if testCase.errMessage != "" {
+				require.Error(t, err)
+				assert.Equal(t, testCase.errMessage, err.Error())
+				assert.Nil(t, schedule)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expression, schedule.String())
+		})
+	}
+}
+
+func Test_Schedule_Next(t *testing.T) {
+	t.Parallel()
+
+	parseTime := func(s string) time.Time {
+		t.Helper()
+		parsed, err := time.Parse(time.RFC3339, s)
+		require.NoError(t, err)
+		return parsed
+	}
+
+	testCases := map[string]struct {
+		expression string
+		after      string
+		next       string
+	}{
+		"every minute": {
+			expression: "* * * * *",
+			after:      "2023-03-10T10:20:30Z",
+			next:       "2023-03-10T10:21:00Z",
+		},
+		"exactly on activation": {
+			expression: "0 * * * *",
+			after:      "2023-03-10T10:00:00Z",
+			next:       "2023-03-10T11:00:00Z",
+		},
+		"every 15 minutes during business hours": {
+			expression: "*/15 9-17 * * mon-fri",
+			after:      "2023-03-10T17:50:00Z", // Friday
+			next:       "2023-03-13T09:00:00Z", // Monday
+		},
+		"day of month or day of week": {
+			expression: "0 0 15 * sun",
+			after:      "2023-03-10T00:00:00Z",
+			next:       "2023-03-12T00:00:00Z",
+		},
+		"day of month step and day of week": {
+			expression: "0 0 */2 * mon",
+			after:      "2023-03-10T00:00:00Z",
+			next:       "2023-03-13T00:00:00Z", // odd day and Monday
+		},
+		"day of month and day of week step": {
+			expression: "0 0 13 * */2",
+			after:      "2023-03-10T00:00:00Z",
+			next:       "2023-04-13T00:00:00Z", // 13th and Thursday
+		},
+		"sunday as 7": {
+			expression: "0 0 * * 7",
+			after:      "2023-03-10T00:00:00Z",
+			next:       "2023-03-12T00:00:00Z",
+		},
+		"next year": {
+			expression: "@yearly",
+			after:      "2023-03-10T00:00:00Z",
+			next:       "2024-01-01T00:00:00Z",
+		},
+		"never": {
+			expression: "0 0 30 2 *",
+			after:      "2023-03-10T00:00:00Z",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			schedule, err := Parse(testCase.expression)
+			require.NoError(t, err)
+
+			next := schedule.Next(parseTime(testCase.after))
+
+			if testCase.next == "" {
+				assert.True(t, next.IsZero())
+				return
+			}
+			assert.Equal(t, parseTime(testCase.next), next)
+		})
+	}
+}
@@ -8,14 +8,31 @@ import (
 
 type Database struct {
 	data []records.Record
+	// indices maps record IDs to their index in data.
+	indices map[string]int
 	sync.RWMutex
 	persistentDB PersistentDatabase
+	// onIPChange is called after a new IP address is persisted,
+	// and can be nil.
+	onIPChange func()
 }
 
-// NewDatabase creates a new in memory database.
-func NewDatabase(data []records.Record, persistentDB PersistentDatabase) *Database {
+// NewDatabase creates a new in memory database. The function onIPChange,
+// if not nil, is called each time a new IP address is persisted.
+func NewDatabase(data []records.Record, persistentDB PersistentDatabase,
+	onIPChange func()) *Database {
 	return &Database{
 		data:         data,
+		indices:      makeIndices(data),
 		persistentDB: persistentDB,
+		onIPChange:   onIPChange,
 	}
 }
+
+func makeIndices(data []records.Record) (indices map[string]int) {
+	indices = make(map[string]int, len(data))
+	for i, record := range data {
+		indices[record.ID] = i
+	}
+	return indices
+}
@@ -0,0 +1,95 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/qdm12/ddns-updater/internal/settings"
+)
+
+// SelectEvents returns the IP address changes of the records
+// matching the filter given, ordered by record and then by time.
+func (db *Database) SelectEvents(filter models.HistoryFilter) (events []models.RecordEvent) {
+	db.RLock()
+	defer db.RUnlock()
+	for _, record := range db.data {
+		key := settings.Key(record.Settings)
+		for _, event := range record.History {
+			if !filter.Match(record.ID, event) {
+				continue
+			}
+			events = append(events, models.RecordEvent{
+				RecordID:  record.ID,
+				Provider:  key.Provider,
+				Domain:    key.Domain,
+				Host:      key.Host,
+				IPVersion: key.IPVersion.String(),
+				IP:        event.IP,
+				Time:      event.Time,
+			})
+		}
+	}
+	return events
+}
+
+// ImportEvents merges the events given into the history of the records
+// they belong to, skipping duplicate events, and applies the retention
+// policy at the time now. An event belongs to the record with the same
+// identifier or, if there is none, with the same provider, domain, host
+// and IP version. Events belonging to no record are skipped. The
+// onIPChange function is called if at least one event is added.
+func (db *Database) ImportEvents(events []models.RecordEvent, now time.Time) (
+	result models.ImportResult, err error) {
+	db.Lock()
+	defer db.Unlock()
+
+	indexToEvents := make(map[int][]models.HistoryEvent)
+	for _, event := range events {
+		index, ok := db.findEventRecord(event)
+		if !ok {
+			result.Unmatched++
+			continue
+		}
+		indexToEvents[index] = append(indexToEvents[index],
+			models.HistoryEvent{IP: event.IP, Time: event.Time})
+	}
+
+	for index, historyEvents := range indexToEvents {
+		record := db.data[index]
+		key := settings.Key(record.Settings)
+		added, err := db.persistentDB.MergeEvents(key, historyEvents, now)
+		if err != nil {
+			return result, fmt.Errorf("for record %s: %w", record.ID, err)
+		}
+		result.Added += added
+		result.Duplicates += len(historyEvents) - added
+
+		// the history may be pruned by the persistent database
+		db.data[index].History, err = db.persistentDB.GetEvents(key)
+		if err != nil {
+			return result, fmt.Errorf("for record %s: %w", record.ID, err)
+		}
+	}
+	if result.Added > 0 && db.onIPChange != nil {
+		db.onIPChange()
+	}
+	return result, nil
+}
+
+// findEventRecord returns the index of the record the event belongs to,
+// and false if it belongs to no record.
+func (db *Database) findEventRecord(event models.RecordEvent) (index int, ok bool) {
+	index, ok = db.indices[event.RecordID]
+	if ok {
+		return index, true
+	}
+	for i, record := range db.data {
+		key := settings.Key(record.Settings)
+		if key.Provider == event.Provider && key.Domain == event.Domain &&
+			key.Host == event.Host && key.IPVersion.String() == event.IPVersion {
+			return i, true
+		}
+	}
+	return 0, false
+}
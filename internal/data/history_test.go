@@ -0,0 +1,63 @@
+package data
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/qdm12/ddns-updater/internal/records"
+	"github.com/qdm12/ddns-updater/internal/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (db *testPersistentDB) MergeEvents(key models.RecordKey, events []models.HistoryEvent,
+	_ time.Time) (added int, err error) {
+	db.events[key.Host], added = models.MergeEvents(db.events[key.Host], events)
+	return added, nil
+}
+
+func Test_Database_ImportEvents(t *testing.T) {
+	t.Parallel()
+
+	a := &testSettings{host: "a"}
+	b := &testSettings{host: "b"}
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	existing := models.HistoryEvent{IP: net.IP{1, 1, 1, 1}, Time: t0}
+	persistentDB := &testPersistentDB{
+		events: map[string][]models.HistoryEvent{"a": {existing}},
+	}
+	ipChanges := 0
+	db := NewDatabase([]records.Record{
+		{ID: settings.ID(a), Settings: a, History: models.History{existing}},
+		{ID: settings.ID(b), Settings: b},
+	}, persistentDB, func() { ipChanges++ })
+
+	older := models.HistoryEvent{IP: net.IP{2, 2, 2, 2}, Time: t0.Add(-time.Hour)}
+	result, err := db.ImportEvents([]models.RecordEvent{
+		{RecordID: settings.ID(a), IP: older.IP, Time: older.Time},
+		{RecordID: settings.ID(a), IP: existing.IP, Time: existing.Time},
+		// matched by provider, domain, host and IP version
+		{RecordID: "other", Provider: "test", Domain: "example.com", Host: "b",
+			IPVersion: "ipv4", IP: existing.IP, Time: existing.Time},
+		{RecordID: "other", Provider: "test", Domain: "example.com", Host: "c",
+			IPVersion: "ipv4", IP: existing.IP, Time: existing.Time},
+	}, t0)
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportResult{Added: 2, Duplicates: 1, Unmatched: 1}, result)
+	assert.Equal(t, 1, ipChanges)
+	records := db.SelectAll()
+	assert.Equal(t, models.History{older, existing}, records[0].History)
+	assert.Equal(t, models.History{existing}, records[1].History)
+
+	events := db.SelectEvents(models.HistoryFilter{From: t0})
+	expectedEvents := []models.RecordEvent{
+		{RecordID: settings.ID(a), Provider: "test", Domain: "example.com", Host: "a",
+			IPVersion: "ipv4", IP: existing.IP, Time: existing.Time},
+		{RecordID: settings.ID(b), Provider: "test", Domain: "example.com", Host: "b",
+			IPVersion: "ipv4", IP: existing.IP, Time: existing.Time},
+	}
+	assert.Equal(t, expectedEvents, events)
+}
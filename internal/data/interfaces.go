@@ -1,6 +1,7 @@
 package data
 
 import (
+	"io"
 	"net"
 	"time"
 
@@ -9,7 +10,14 @@ import (
 
 type PersistentDatabase interface {
 	Close() error
-	StoreNewIP(domain, host string, ip net.IP, t time.Time) (err error)
-	GetEvents(domain, host string) (events []models.HistoryEvent, err error)
+	StoreNewIP(key models.RecordKey, ip net.IP, t time.Time) (err error)
+	GetEvents(key models.RecordKey) (events []models.HistoryEvent, err error)
+	MergeEvents(key models.RecordKey, events []models.HistoryEvent,
+		now time.Time) (added int, err error)
+	StoreState(key models.RecordKey, state models.RecordState) (err error)
+	GetState(key models.RecordKey) (state models.RecordState, err error)
+	Migrate(keys []models.RecordKey) (err error)
+	Compact(now time.Time) (results []models.CompactResult, err error)
 	Check() error
+	WriteTo(w io.Writer) (n int64, err error)
 }
@@ -9,17 +9,19 @@ import (
 
 var ErrRecordNotFound = errors.New("record not found")
 
-func (db *Database) Select(id uint) (record records.Record, err error) {
+func (db *Database) Select(id string) (record records.Record, err error) {
 	db.RLock()
 	defer db.RUnlock()
-	if int(id) > len(db.data)-1 {
-		return record, fmt.Errorf("%w: for id %d", ErrRecordNotFound, id)
+	index, ok := db.indices[id]
+	if !ok {
+		return record, fmt.Errorf("%w: for id %s", ErrRecordNotFound, id)
 	}
-	return db.data[id], nil
+	return db.data[index], nil
 }
 
 func (db *Database) SelectAll() (records []records.Record) {
 	db.RLock()
 	defer db.RUnlock()
-	return db.data
+	records = append(records, db.data...)
+	return records
 }
@@ -5,31 +5,49 @@ import (
 
 	"github.com/qdm12/ddns-updater/internal/models"
 	"github.com/qdm12/ddns-updater/internal/records"
+	"github.com/qdm12/ddns-updater/internal/settings"
 )
 
-func (db *Database) GetEvents(domain, host string) (events []models.HistoryEvent, err error) {
-	return db.persistentDB.GetEvents(domain, host)
+func (db *Database) GetEvents(key models.RecordKey) (events []models.HistoryEvent, err error) {
+	return db.persistentDB.GetEvents(key)
 }
 
-func (db *Database) Update(id uint, record records.Record) (err error) {
+func (db *Database) Update(id string, record records.Record) (err error) {
 	db.Lock()
 	defer db.Unlock()
-	if int(id) > len(db.data)-1 {
-		return fmt.Errorf("%w: for id %d", ErrRecordNotFound, id)
+	index, ok := db.indices[id]
+	if !ok {
+		return fmt.Errorf("%w: for id %s", ErrRecordNotFound, id)
 	}
-	currentCount := len(db.data[id].History)
+	currentCount := len(db.data[index].History)
 	newCount := len(record.History)
-	db.data[id] = record
-	// new IP address added
-	if newCount > currentCount {
+	stateChanged := !db.data[index].State().Equal(record.State())
+	db.data[index] = record
+	key := settings.Key(record.Settings)
+	ipChanged := newCount > currentCount
+	if ipChanged {
 		if err := db.persistentDB.StoreNewIP(
-			record.Settings.Domain(),
-			record.Settings.Host(),
+			key,
 			record.History.GetCurrentIP(),
 			record.History.GetSuccessTime(),
 		); err != nil {
 			return err
 		}
+		// the history may be pruned by the persistent database
+		events, err := db.persistentDB.GetEvents(key)
+		if err != nil {
+			return err
+		}
+		db.data[index].History = events
+	}
+	if stateChanged {
+		err = db.persistentDB.StoreState(key, record.State())
+		if err != nil {
+			return err
+		}
+	}
+	if ipChanged && db.onIPChange != nil {
+		db.onIPChange()
 	}
 	return nil
 }
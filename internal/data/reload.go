@@ -0,0 +1,68 @@
+package data
+
+import (
+	"fmt"
+
+	"github.com/qdm12/ddns-updater/internal/constants"
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/qdm12/ddns-updater/internal/records"
+	"github.com/qdm12/ddns-updater/internal/settings"
+)
+
+// Reload reconciles the records in memory with the settings given.
+// A record with the same ID as an existing record keeps the history
+// and status of the existing record. New records are created from the
+// persistent database, and existing records not found in the settings
+// given are dropped.
+func (db *Database) Reload(allSettings []settings.Settings) (err error) {
+	db.Lock()
+	defer db.Unlock()
+
+	newKeys := make([]models.RecordKey, 0, len(allSettings))
+	for _, s := range allSettings {
+		if _, ok := db.indices[settings.ID(s)]; !ok {
+			newKeys = append(newKeys, settings.Key(s))
+		}
+	}
+	err = db.persistentDB.Migrate(newKeys)
+	if err != nil {
+		return fmt.Errorf("migrating persisted records: %w", err)
+	}
+
+	data := make([]records.Record, len(allSettings))
+	for i, s := range allSettings {
+		id := settings.ID(s)
+		if index, ok := db.indices[id]; ok {
+			data[i] = reloadRecord(db.data[index], s)
+			continue
+		}
+
+		key := settings.Key(s)
+		events, err := db.persistentDB.GetEvents(key)
+		if err != nil {
+			return err
+		}
+		state, err := db.persistentDB.GetState(key)
+		if err != nil {
+			return err
+		}
+		data[i] = records.New(s, events, state)
+	}
+
+	db.data = data
+	db.indices = makeIndices(data)
+	return nil
+}
+
+// reloadRecord returns the record with its settings replaced. A record
+// disabled due to a permanent error is enabled again if its settings
+// changed, since the change may fix the error.
+func reloadRecord(record records.Record, s settings.Settings) records.Record {
+	if record.Status == constants.DISABLED && settings.Fingerprint(record.Settings) != settings.Fingerprint(s) {
+		record.Status = constants.UNSET
+		record.Message = ""
+		record.ErrorCategory = ""
+	}
+	record.Settings = s
+	return record
+}
@@ -0,0 +1,99 @@
+package data
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/constants"
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/qdm12/ddns-updater/internal/records"
+	"github.com/qdm12/ddns-updater/internal/settings"
+	settingsconstants "github.com/qdm12/ddns-updater/internal/settings/constants"
+	"github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSettings struct {
+	settings.Settings
+	host string
+}
+
+func (s *testSettings) Provider() models.Provider      { return "test" }
+func (s *testSettings) Domain() string                 { return "example.com" }
+func (s *testSettings) Host() string                   { return s.host }
+func (s *testSettings) IPVersion() ipversion.IPVersion { return ipversion.IP4 }
+
+type testPersistentDB struct {
+	PersistentDatabase
+	events map[string][]models.HistoryEvent
+}
+
+func (db *testPersistentDB) Migrate([]models.RecordKey) (err error) {
+	return nil
+}
+
+func (db *testPersistentDB) GetEvents(key models.RecordKey) (events []models.HistoryEvent, err error) {
+	return db.events[key.Host], nil
+}
+
+func (db *testPersistentDB) GetState(models.RecordKey) (state models.RecordState, err error) {
+	return state, nil
+}
+
+// newOVHSettings returns new OVH settings, which cannot be
+// compared with reflect.DeepEqual since they contain a function.
+func newOVHSettings(t *testing.T) settings.Settings {
+	t.Helper()
+	const raw = `{"provider":"ovh","domain":"example.com","host":"@",` +
+		`"username":"user","password":"password"}`
+	s, err := settings.New(settingsconstants.OVH, json.RawMessage(raw),
+		"example.com", "@", ipversion.IP4)
+	require.NoError(t, err)
+	return settings.WithCommon(s, settings.Common{Raw: raw})
+}
+
+func Test_Database_Reload(t *testing.T) {
+	t.Parallel()
+
+	kept := &testSettings{host: "kept"}
+	changed := settings.WithCommon(&testSettings{host: "changed"},
+		settings.Common{Raw: `{"token":"a"}`})
+	ovhSettings := newOVHSettings(t)
+	removed := &testSettings{host: "removed"}
+	time1 := time.Unix(1, 0)
+	persistentDB := &testPersistentDB{
+		events: map[string][]models.HistoryEvent{
+			"added": {{IP: net.IP{1, 2, 3, 4}, Time: time1}},
+		},
+	}
+	db := NewDatabase([]records.Record{
+		{ID: settings.ID(removed), Settings: removed, Status: constants.SUCCESS},
+		{ID: settings.ID(changed), Settings: changed, Status: constants.DISABLED, Message: "bad token"},
+		{ID: settings.ID(kept), Settings: kept, Status: constants.SUCCESS, Message: "ok"},
+		{ID: settings.ID(ovhSettings), Settings: ovhSettings, Status: constants.DISABLED,
+			Message: "bad credentials"},
+	}, persistentDB, nil)
+
+	changedAgain := settings.WithCommon(&testSettings{host: "changed"},
+		settings.Common{Raw: `{"token":"b"}`})
+	ovhUnchanged := newOVHSettings(t)
+	added := &testSettings{host: "added"}
+	err := db.Reload([]settings.Settings{kept, added, changedAgain, ovhUnchanged})
+	require.NoError(t, err)
+
+	expectedRecords := []records.Record{
+		{ID: settings.ID(kept), Settings: kept, Status: constants.SUCCESS, Message: "ok"},
+		{ID: settings.ID(added), Settings: added, Status: constants.UNSET,
+			History: models.History{{IP: net.IP{1, 2, 3, 4}, Time: time1}}},
+		{ID: settings.ID(changed), Settings: changedAgain, Status: constants.UNSET},
+		{ID: settings.ID(ovhSettings), Settings: ovhUnchanged, Status: constants.DISABLED,
+			Message: "bad credentials"},
+	}
+	assert.Equal(t, expectedRecords, db.SelectAll())
+
+	_, err = db.Select(settings.ID(removed))
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+}
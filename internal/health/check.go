@@ -25,7 +25,7 @@ var (
 func isHealthy(db AllSelecter, resolver LookupIPer) (err error) {
 	records := db.SelectAll()
 	for _, record := range records {
-		if record.Status == constants.FAIL {
+		if record.Status == constants.FAIL || record.Status == constants.DISABLED {
 			return fmt.Errorf("%w: %s", ErrRecordUpdateFailed, record.String())
 		} else if record.Settings.Proxied() {
 			continue
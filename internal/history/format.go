@@ -0,0 +1,181 @@
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+)
+
+type Format string
+
+const (
+	CSV  Format = "csv"
+	JSON Format = "json"
+)
+
+var ErrFormatUnknown = errors.New("format is unknown")
+
+// ParseFormat parses the format from the string given,
+// which can be csv or json and is case insensitive.
+func ParseFormat(s string) (format Format, err error) {
+	switch format := Format(strings.ToLower(s)); format {
+	case CSV, JSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrFormatUnknown, s)
+	}
+}
+
+// ContentType returns the HTTP content type of the format.
+func (f Format) ContentType() string {
+	if f == CSV {
+		return "text/csv"
+	}
+	return "application/json"
+}
+
+//nolint:gochecknoglobals
+var csvHeader = []string{"record_id", "provider", "domain", "host", "ip_version", "ip", "time"}
+
+// Encode writes the events given to w in the format given.
+// For the CSV format, the first line is a header line and
+// times are written as RFC3339 times.
+func Encode(w io.Writer, format Format, events []models.RecordEvent) (err error) {
+	if format == JSON {
+		if events == nil {
+			events = []models.RecordEvent{} // encode as [] instead of null
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(events)
+	}
+
+	writer := csv.NewWriter(w)
+	err = writer.Write(csvHeader)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		err = writer.Write([]string{event.RecordID, string(event.Provider), event.Domain,
+			event.Host, event.IPVersion, event.IP.String(), event.Time.Format(time.RFC3339Nano)})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+var (
+	ErrColumnMissing  = errors.New("column is missing")
+	ErrIPMissing      = errors.New("IP address is missing")
+	ErrTimeMissing    = errors.New("time is missing")
+	ErrRecordMissing  = errors.New("record identifier or domain is missing")
+	ErrIPAddressParse = errors.New("cannot parse IP address")
+)
+
+// Decode reads events from r in the format given, and returns an
+// error if an event has no IP address, no time or identifies no record.
+// For the CSV format, the first line must be a header line containing
+// at least the ip and time columns, and columns can be in any order.
+func Decode(r io.Reader, format Format) (events []models.RecordEvent, err error) {
+	if format == JSON {
+		err = json.NewDecoder(r).Decode(&events)
+		if err != nil {
+			return nil, fmt.Errorf("decoding JSON: %w", err)
+		}
+	} else {
+		events, err = decodeCSV(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i, event := range events {
+		err = validate(event)
+		if err != nil {
+			return nil, fmt.Errorf("event %d of %d: %w", i+1, len(events), err)
+		}
+	}
+	return events, nil
+}
+
+func decodeCSV(r io.Reader) (events []models.RecordEvent, err error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // checked below
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+
+	columnToIndex := make(map[string]int, len(header))
+	for i, column := range header {
+		columnToIndex[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range []string{"ip", "time"} {
+		if _, ok := columnToIndex[column]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrColumnMissing, column)
+		}
+	}
+
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("reading CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		get := func(column string) string {
+			index, ok := columnToIndex[column]
+			if !ok || index >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[index])
+		}
+
+		event := models.RecordEvent{
+			RecordID:  get("record_id"),
+			Provider:  models.Provider(get("provider")),
+			Domain:    get("domain"),
+			Host:      get("host"),
+			IPVersion: get("ip_version"),
+		}
+		if s := get("ip"); s != "" {
+			event.IP = net.ParseIP(s)
+			if event.IP == nil {
+				return nil, fmt.Errorf("line %d: %w: %s", line, ErrIPAddressParse, s)
+			}
+		}
+		if s := get("time"); s != "" {
+			event.Time, err = time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: parsing time: %w", line, err)
+			}
+		}
+		events = append(events, event)
+	}
+}
+
+func validate(event models.RecordEvent) (err error) {
+	switch {
+	case event.IP == nil:
+		return fmt.Errorf("%w", ErrIPMissing)
+	case event.Time.IsZero():
+		return fmt.Errorf("%w", ErrTimeMissing)
+	case event.RecordID == "" && event.Domain == "":
+		return fmt.Errorf("%w", ErrRecordMissing)
+	}
+	return nil
+}
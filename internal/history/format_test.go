@@ -0,0 +1,131 @@
+package history
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Encode_Decode(t *testing.T) {
+	t.Parallel()
+
+	events := []models.RecordEvent{{
+		RecordID:  "a",
+		Provider:  "duckdns",
+		Domain:    "duckdns.org",
+		Host:      "example",
+		IPVersion: "ipv4",
+		IP:        net.ParseIP("1.2.3.4"),
+		Time:      time.Date(2020, 1, 1, 0, 0, 0, 1, time.UTC),
+	}}
+
+	for _, format := range []Format{CSV, JSON} {
+		format := format
+		t.Run(string(format), func(t *testing.T) {
+			t.Parallel()
+
+			buffer := bytes.NewBuffer(nil)
+			err := Encode(buffer, format, events)
+			require.NoError(t, err)
+
+			decoded, err := Decode(buffer, format)
+			require.NoError(t, err)
+			assert.Equal(t, events, decoded)
+		})
+	}
+}
+
+func Test_Encode_CSV(t *testing.T) {
+	t.Parallel()
+
+	buffer := bytes.NewBuffer(nil)
+	err := Encode(buffer, CSV, []models.RecordEvent{{
+		RecordID: "a",
+		Domain:   "example.com",
+		Host:     "@",
+		IP:       net.IPv4(1, 2, 3, 4),
+		Time:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}})
+
+	require.NoError(t, err)
+	const expected = "record_id,provider,domain,host,ip_version,ip,time\n" +
+		"a,,example.com,@,,1.2.3.4,2020-01-01T00:00:00Z\n"
+	assert.Equal(t, expected, buffer.String())
+}
+
+func Test_Decode(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		format     Format
+		data       string
+		events     []models.RecordEvent
+		errWrapped error
+		errMessage string
+	}{
+		"empty CSV": {
+			format: CSV,
+		},
+		"CSV columns in any order": {
+			format: CSV,
+			data:   "time,ip,host,domain\n2020-01-01T00:00:00Z, 1.2.3.4 ,@,example.com\n",
+			events: []models.RecordEvent{{
+				Domain: "example.com",
+				Host:   "@",
+				IP:     net.ParseIP("1.2.3.4"),
+				Time:   time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+		"CSV column missing": {
+			format:     CSV,
+			data:       "record_id,ip\na,1.2.3.4\n",
+			errWrapped: ErrColumnMissing,
+			errMessage: "column is missing: time",
+		},
+		"CSV malformed IP address": {
+			format:     CSV,
+			data:       "record_id,ip,time\na,1.2.3.4,2020-01-01T00:00:00Z\na,1.2.3,2020-01-01T00:00:00Z\n",
+			errWrapped: ErrIPAddressParse,
+			errMessage: "line 3: cannot parse IP address: 1.2.3",
+		},
+		"CSV IP address missing": {
+			format:     CSV,
+			data:       "record_id,ip,time\na,,2020-01-01T00:00:00Z\n",
+			errWrapped: ErrIPMissing,
+			errMessage: "event 1 of 1: IP address is missing",
+		},
+		"JSON time missing": {
+			format:     JSON,
+			data:       `[{"record_id":"a","ip":"1.2.3.4"}]`,
+			errWrapped: ErrTimeMissing,
+			errMessage: "event 1 of 1: time is missing",
+		},
+		"JSON record missing": {
+			format:     JSON,
+			data:       `[{"ip":"1.2.3.4","time":"2020-01-01T00:00:00Z"}]`,
+			errWrapped: ErrRecordMissing,
+			errMessage: "event 1 of 1: record identifier or domain is missing",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			events, err := Decode(strings.NewReader(testCase.data), testCase.format)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.events, events)
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+)
+
+type UpdateForcer interface {
+	ForceUpdate(ctx context.Context) (results []models.AuditEntry, errs []error)
+}
@@ -0,0 +1,146 @@
+// Package jobs runs update jobs in the background and keeps
+// a bounded history of the recent jobs in memory.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+)
+
+// Status is the status of a job.
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Job is an update of all the records running in the background.
+type Job struct {
+	ID      string    `json:"id"`
+	Status  Status    `json:"status"`
+	Started time.Time `json:"started"`
+	// Finished is nil if the job is still running.
+	Finished *time.Time `json:"finished,omitempty"`
+	// Records contains the decision and outcome for each
+	// record, once the job is finished.
+	Records []models.AuditEntry `json:"records,omitempty"`
+	Errors  []string            `json:"errors,omitempty"`
+}
+
+type Manager struct {
+	runner  UpdateForcer
+	maxJobs int
+	// jobs are the recent jobs, from oldest to newest.
+	jobs    []*Job
+	mutex   sync.RWMutex
+	timeNow func() time.Time
+}
+
+// NewManager creates a job manager keeping at most maxJobs jobs in memory.
+func NewManager(runner UpdateForcer, maxJobs int, timeNow func() time.Time) *Manager {
+	return &Manager{
+		runner:  runner,
+		maxJobs: maxJobs,
+		timeNow: timeNow,
+	}
+}
+
+// Start starts a job updating all the records in the background, and
+// returns it with started set to true. If a job is already running,
+// it is returned instead with started set to false, so concurrent
+// requests do not queue up updates. The job runs until it finishes
+// or until the context given is canceled.
+func (m *Manager) Start(ctx context.Context) (job Job, started bool, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if len(m.jobs) > 0 {
+		last := m.jobs[len(m.jobs)-1]
+		if last.Status == StatusRunning {
+			return *last, false, nil
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return job, false, fmt.Errorf("generating job id: %w", err)
+	}
+	newJob := &Job{
+		ID:      id,
+		Status:  StatusRunning,
+		Started: m.timeNow(),
+	}
+	m.jobs = append(m.jobs, newJob)
+	if len(m.jobs) > m.maxJobs {
+		// the running job is the newest job, so it is never removed
+		m.jobs = append([]*Job(nil), m.jobs[len(m.jobs)-m.maxJobs:]...)
+	}
+
+	go m.run(ctx, newJob)
+
+	return *newJob, true, nil
+}
+
+func (m *Manager) run(ctx context.Context, job *Job) {
+	results, errs := m.runner.ForceUpdate(ctx)
+	finished := m.timeNow()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	job.Finished = &finished
+	job.Records = results
+	job.Status = StatusSucceeded
+	if len(errs) > 0 {
+		job.Status = StatusFailed
+		job.Errors = make([]string, len(errs))
+		for i, err := range errs {
+			job.Errors[i] = err.Error()
+		}
+	}
+}
+
+var ErrJobNotFound = errors.New("job not found")
+
+// Get returns the job with the identifier given, or an
+// error if the job does not exist or was removed from
+// the history of recent jobs.
+func (m *Manager) Get(id string) (job Job, err error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	for _, job := range m.jobs {
+		if job.ID == id {
+			return *job, nil
+		}
+	}
+	return job, fmt.Errorf("%w: for id %s", ErrJobNotFound, id)
+}
+
+// List returns the recent jobs, from newest to oldest.
+func (m *Manager) List() (jobs []Job) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	jobs = make([]Job, len(m.jobs))
+	for i, job := range m.jobs {
+		jobs[len(m.jobs)-1-i] = *job
+	}
+	return jobs
+}
+
+func newID() (id string, err error) {
+	const idBytes = 8
+	b := make([]byte, idBytes)
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
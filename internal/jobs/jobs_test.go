@@ -0,0 +1,117 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRunner struct {
+	results chan []models.AuditEntry
+	errs    []error
+}
+
+func (r *testRunner) ForceUpdate(ctx context.Context) (
+	results []models.AuditEntry, errs []error) {
+	select {
+	case results = <-r.results:
+		return results, r.errs
+	case <-ctx.Done():
+		return nil, []error{ctx.Err()}
+	}
+}
+
+// waitFinished waits for the job with the identifier given to finish.
+func waitFinished(t *testing.T, manager *Manager, id string) (job Job) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = manager.Get(id)
+		require.NoError(t, err)
+		return job.Status != StatusRunning
+	}, time.Second, time.Millisecond)
+	return job
+}
+
+func Test_Manager(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow := func() time.Time { return now }
+	runner := &testRunner{results: make(chan []models.AuditEntry)}
+	const maxJobs = 2
+	manager := NewManager(runner, maxJobs, timeNow)
+	ctx := context.Background()
+
+	job, started, err := manager.Start(ctx)
+	require.NoError(t, err)
+	assert.True(t, started)
+	assert.Equal(t, StatusRunning, job.Status)
+	assert.Len(t, job.ID, 16)
+	firstID := job.ID
+
+	// a job is already running
+	job, started, err = manager.Start(ctx)
+	require.NoError(t, err)
+	assert.False(t, started)
+	assert.Equal(t, firstID, job.ID)
+
+	entries := []models.AuditEntry{{RecordID: "a", Action: "update"}}
+	runner.results <- entries
+	job = waitFinished(t, manager, firstID)
+	expected := Job{
+		ID:       firstID,
+		Status:   StatusSucceeded,
+		Started:  now,
+		Finished: &now,
+		Records:  entries,
+	}
+	assert.Equal(t, expected, job)
+
+	runner.errs = []error{errors.New("test error")}
+	job, started, err = manager.Start(ctx)
+	require.NoError(t, err)
+	require.True(t, started)
+	secondID := job.ID
+	runner.results <- nil
+	job = waitFinished(t, manager, secondID)
+	assert.Equal(t, StatusFailed, job.Status)
+	assert.Equal(t, []string{"test error"}, job.Errors)
+
+	// the oldest job is removed
+	job, started, err = manager.Start(ctx)
+	require.NoError(t, err)
+	require.True(t, started)
+	thirdID := job.ID
+	_, err = manager.Get(firstID)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+
+	jobs := manager.List()
+	require.Len(t, jobs, maxJobs)
+	assert.Equal(t, thirdID, jobs[0].ID)
+	assert.Equal(t, secondID, jobs[1].ID)
+
+	runner.results <- nil
+	waitFinished(t, manager, thirdID)
+}
+
+func Test_Manager_canceled(t *testing.T) {
+	t.Parallel()
+
+	runner := &testRunner{results: make(chan []models.AuditEntry)}
+	manager := NewManager(runner, 1, time.Now)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	job, _, err := manager.Start(ctx)
+	require.NoError(t, err)
+	cancel()
+
+	job = waitFinished(t, manager, job.ID)
+	assert.Equal(t, StatusFailed, job.Status)
+	assert.Equal(t, []string{context.Canceled.Error()}, job.Errors)
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"net"
+	"time"
+)
+
+// AuditEntry is an entry of the audit log, recording
+// a decision taken for a record and its outcome.
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	RecordID string    `json:"record_id"`
+	Provider Provider  `json:"provider"`
+	Domain   string    `json:"domain"`
+	Host     string    `json:"host"`
+	// DetectedIP is the public IP address detected
+	// for the IP version of the record.
+	DetectedIP net.IP `json:"detected_ip,omitempty"`
+	// ResolvedIP is the IP address the record resolved to, and
+	// is nil if it could not be resolved or was not resolved.
+	ResolvedIP net.IP `json:"resolved_ip,omitempty"`
+	Action     string `json:"action"`
+	// Status is the status of the record after the action.
+	Status Status `json:"status"`
+	// Response is a summary of the provider response for an update,
+	// or the reason for the decision otherwise.
+	Response      string `json:"response,omitempty"`
+	ErrorCategory string `json:"error_category,omitempty"`
+	// Duration is the duration of the provider update for
+	// an update, and of the decision otherwise.
+	Duration time.Duration `json:"duration"`
+}
+
+// AuditFilter is a filter to query audit log entries.
+type AuditFilter struct {
+	// RecordID is the record identifier to match,
+	// and empty to match all records.
+	RecordID string
+	// From is the time from which to match entries, and
+	// the zero time to match entries from the start.
+	From time.Time
+	// To is the time up to which to match entries, and
+	// the zero time to match entries until the end.
+	To time.Time
+	// Limit is the maximum number of entries to return, keeping
+	// the most recent entries, and zero means no limit.
+	Limit int
+}
+
+// Match returns true if the entry matches the filter,
+// regardless of the filter limit.
+func (f AuditFilter) Match(entry AuditEntry) bool {
+	return (f.RecordID == "" || f.RecordID == entry.RecordID) &&
+		(f.From.IsZero() || !entry.Time.Before(f.From)) &&
+		(f.To.IsZero() || !entry.Time.After(f.To))
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Backoff contains the retry state of a record after
+// consecutive update failures.
+type Backoff struct {
+	Failures  uint      `json:"failures"`
+	RetryTime time.Time `json:"retry_time"` // zero means no scheduled retry
+}
+
+// Equal returns true if both backoff states are the same.
+func (b Backoff) Equal(other Backoff) bool {
+	return b.Failures == other.Failures && b.RetryTime.Equal(other.RetryTime)
+}
+
+// IsWithin returns true if the retry time is after now.
+func (b Backoff) IsWithin(now time.Time) bool {
+	return !b.RetryTime.IsZero() && now.Before(b.RetryTime)
+}
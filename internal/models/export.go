@@ -0,0 +1,51 @@
+package models
+
+import (
+	"net"
+	"time"
+)
+
+// RecordEvent is an IP address change of a record,
+// as exported and imported.
+type RecordEvent struct {
+	RecordID  string   `json:"record_id"`
+	Provider  Provider `json:"provider"`
+	Domain    string   `json:"domain"`
+	Host      string   `json:"host"`
+	IPVersion string   `json:"ip_version"`
+	IP        net.IP   `json:"ip"`
+	// Time is the time the IP address was set for the record.
+	Time time.Time `json:"time"`
+}
+
+// HistoryFilter is a filter to select record events.
+type HistoryFilter struct {
+	// RecordID is the record identifier to match,
+	// and empty to match all records.
+	RecordID string
+	// From is the time from which to match events, and
+	// the zero time to match events from the start.
+	From time.Time
+	// To is the time up to which to match events, and
+	// the zero time to match events until the end.
+	To time.Time
+}
+
+// Match returns true if the event of the record
+// with the identifier given matches the filter.
+func (f HistoryFilter) Match(recordID string, event HistoryEvent) bool {
+	return (f.RecordID == "" || f.RecordID == recordID) &&
+		(f.From.IsZero() || !event.Time.Before(f.From)) &&
+		(f.To.IsZero() || !event.Time.After(f.To))
+}
+
+// ImportResult is the result of importing record events.
+type ImportResult struct {
+	// Added is the number of events added to the history of records.
+	Added int `json:"added"`
+	// Duplicates is the number of events already
+	// in the history of records, and not added.
+	Duplicates int `json:"duplicates"`
+	// Unmatched is the number of events matching no record, and not added.
+	Unmatched int `json:"unmatched"`
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"fmt"
 	"net"
+	"sort"
 	"strings"
 	"time"
 )
@@ -94,3 +95,39 @@ func (h History) String() (s string) {
 		strings.Join(previousIPsStr, ","),
 	)
 }
+
+// MergeEvents returns the events given merged with the new events
+// given, ordered by time and without duplicate events, as well as the
+// number of new events added. Events are duplicates if they have the
+// same IP address and time.
+func MergeEvents(events, newEvents []HistoryEvent) (merged []HistoryEvent, added int) {
+	type eventKey struct {
+		ip   string
+		time int64
+	}
+	makeKey := func(event HistoryEvent) eventKey {
+		return eventKey{ip: string(event.IP.To16()), time: event.Time.UnixNano()}
+	}
+
+	merged = make([]HistoryEvent, 0, len(events)+len(newEvents))
+	merged = append(merged, events...)
+	keys := make(map[eventKey]struct{}, len(merged))
+	for _, event := range merged {
+		keys[makeKey(event)] = struct{}{}
+	}
+
+	for _, newEvent := range newEvents {
+		key := makeKey(newEvent)
+		if _, duplicate := keys[key]; duplicate {
+			continue
+		}
+		keys[key] = struct{}{}
+		merged = append(merged, newEvent)
+		added++
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Time.Before(merged[j].Time)
+	})
+	return merged, added
+}
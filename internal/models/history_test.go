@@ -1,6 +1,7 @@
 package models
 
 import (
+	"net"
 	"testing"
 	"time"
 
@@ -36,3 +37,53 @@ func Test_GetDurationSinceSuccess(t *testing.T) {
 		})
 	}
 }
+
+func Test_MergeEvents(t *testing.T) {
+	t.Parallel()
+
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	event := func(ip byte, hours int) HistoryEvent {
+		return HistoryEvent{IP: net.IPv4(1, 1, 1, ip), Time: t0.Add(time.Duration(hours) * time.Hour)}
+	}
+
+	testCases := map[string]struct {
+		events    []HistoryEvent
+		newEvents []HistoryEvent
+		merged    []HistoryEvent
+		added     int
+	}{
+		"no event": {
+			merged: []HistoryEvent{},
+		},
+		"ordered by time": {
+			events:    []HistoryEvent{event(1, 1), event(3, 3)},
+			newEvents: []HistoryEvent{event(4, 4), event(2, 2), event(0, 0)},
+			merged:    []HistoryEvent{event(0, 0), event(1, 1), event(2, 2), event(3, 3), event(4, 4)},
+			added:     3,
+		},
+		"duplicates": {
+			events:    []HistoryEvent{event(1, 1)},
+			newEvents: []HistoryEvent{event(1, 1), event(2, 1), event(2, 1)},
+			merged:    []HistoryEvent{event(1, 1), event(2, 1)},
+			added:     1,
+		},
+		"same time in different time zones": {
+			events: []HistoryEvent{event(1, 1)},
+			newEvents: []HistoryEvent{{IP: net.IPv4(1, 1, 1, 1),
+				Time: t0.Add(time.Hour).In(time.FixedZone("UTC+1", 3600))}},
+			merged: []HistoryEvent{event(1, 1)},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			merged, added := MergeEvents(testCase.events, testCase.newEvents)
+
+			assert.Equal(t, testCase.merged, merged)
+			assert.Equal(t, testCase.added, added)
+		})
+	}
+}
@@ -4,11 +4,14 @@ package models
 // It is exported so that the HTML template engine can render it.
 type HTMLData struct {
 	Rows []HTMLRow
+	// Actions is true to show the buttons to update records.
+	Actions bool
 }
 
 // HTMLRow contains HTML fields to be rendered
 // It is exported so that the HTML template engine can render it.
 type HTMLRow struct {
+	ID          HTML
 	Domain      HTML
 	Host        HTML
 	Provider    HTML
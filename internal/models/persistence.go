@@ -4,3 +4,23 @@ type DomainHost struct {
 	Domain string
 	Host   string
 }
+
+// PersistedRecord contains all the data persisted for a record,
+// and is used to copy records between persistent databases.
+type PersistedRecord struct {
+	Key    RecordKey
+	Events []HistoryEvent
+	State  RecordState
+	// Pruned contains the statistics of the events pruned.
+	Pruned HistoryStats
+}
+
+// CompactResult is the result of compacting the history of a record.
+type CompactResult struct {
+	ID     string
+	Kept   int
+	Pruned int
+	// Stats contains the statistics of all the events,
+	// including the events pruned.
+	Stats HistoryStats
+}
@@ -0,0 +1,14 @@
+package models
+
+import "github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
+
+// RecordKey identifies a record in the persistent database.
+// The other fields are used to migrate data persisted before
+// records had an identifier.
+type RecordKey struct {
+	ID        string
+	Provider  Provider
+	Domain    string
+	Host      string
+	IPVersion ipversion.IPVersion
+}
@@ -0,0 +1,114 @@
+package models
+
+import "time"
+
+// Retention is the retention policy of the IP addresses history
+// of records. The latest event of a record is always kept.
+type Retention struct {
+	// MaxEvents is the maximum number of events to keep,
+	// and zero means no maximum.
+	MaxEvents uint
+	// MaxAge is the maximum age of events to keep,
+	// and zero means no maximum.
+	MaxAge time.Duration
+	// DownsampleAge is the age after which events are downsampled
+	// to the latest event of each DownsamplePeriod, and zero
+	// disables downsampling.
+	DownsampleAge    time.Duration
+	DownsamplePeriod time.Duration
+}
+
+// Enabled returns true if the retention policy can prune events.
+func (r Retention) Enabled() bool {
+	return r.MaxEvents > 0 || r.MaxAge > 0 ||
+		(r.DownsampleAge > 0 && r.DownsamplePeriod > 0)
+}
+
+// Keep returns which of the events ordered by time should be kept
+// at the time now according to the retention policy.
+func (r Retention) Keep(events []HistoryEvent, now time.Time) (keep []bool) {
+	keep = make([]bool, len(events))
+	for i := range keep {
+		keep[i] = true
+	}
+	if len(events) == 0 {
+		return keep
+	}
+	last := len(events) - 1
+
+	if r.MaxAge > 0 {
+		for i := 0; i < last; i++ {
+			if now.Sub(events[i].Time) > r.MaxAge {
+				keep[i] = false
+			}
+		}
+	}
+
+	if r.DownsampleAge > 0 && r.DownsamplePeriod > 0 {
+		// keep the latest event of each period, events being ordered by time
+		for i := 0; i < last; i++ {
+			if now.Sub(events[i].Time) <= r.DownsampleAge {
+				continue
+			}
+			period := events[i].Time.Truncate(r.DownsamplePeriod)
+			nextPeriod := events[i+1].Time.Truncate(r.DownsamplePeriod)
+			if period.Equal(nextPeriod) {
+				keep[i] = false
+			}
+		}
+	}
+
+	if r.MaxEvents > 0 {
+		kept := uint(0)
+		for i := last; i >= 0; i-- {
+			if !keep[i] {
+				continue
+			}
+			if kept == r.MaxEvents {
+				keep[i] = false
+				continue
+			}
+			kept++
+		}
+	}
+
+	return keep
+}
+
+// HistoryStats contains aggregate statistics of IP addresses history.
+type HistoryStats struct {
+	// Changes is the number of IP address changes.
+	Changes uint `json:"changes"`
+	// FirstSeen is the time of the first IP address, and
+	// is the zero time if there is no change.
+	FirstSeen time.Time `json:"first_seen"`
+}
+
+// Add returns the statistics with the events given added.
+func (s HistoryStats) Add(events []HistoryEvent) HistoryStats {
+	for _, event := range events {
+		s.Changes++
+		if s.FirstSeen.IsZero() || event.Time.Before(s.FirstSeen) {
+			s.FirstSeen = event.Time
+		}
+	}
+	return s
+}
+
+// Prune removes the events not to keep according to the retention
+// policy, and returns the events kept and the statistics of the
+// pruned events added to the statistics given.
+func (r Retention) Prune(events []HistoryEvent, now time.Time,
+	pruned HistoryStats) (kept []HistoryEvent, newPruned HistoryStats) {
+	keep := r.Keep(events, now)
+	kept = make([]HistoryEvent, 0, len(events))
+	var removed []HistoryEvent
+	for i, event := range events {
+		if keep[i] {
+			kept = append(kept, event)
+		} else {
+			removed = append(removed, event)
+		}
+	}
+	return kept, pruned.Add(removed)
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Retention_Keep(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days float64) HistoryEvent {
+		return HistoryEvent{Time: now.Add(-time.Duration(days * float64(24*time.Hour)))}
+	}
+
+	testCases := map[string]struct {
+		retention Retention
+		events    []HistoryEvent
+		keep      []bool
+	}{
+		"no event": {
+			retention: Retention{MaxEvents: 1},
+			keep:      []bool{},
+		},
+		"disabled": {
+			events: []HistoryEvent{daysAgo(3), daysAgo(2), daysAgo(1)},
+			keep:   []bool{true, true, true},
+		},
+		"max events": {
+			retention: Retention{MaxEvents: 2},
+			events:    []HistoryEvent{daysAgo(3), daysAgo(2), daysAgo(1)},
+			keep:      []bool{false, true, true},
+		},
+		"max age keeps latest event": {
+			retention: Retention{MaxAge: 24 * time.Hour},
+			events:    []HistoryEvent{daysAgo(5), daysAgo(4), daysAgo(3)},
+			keep:      []bool{false, false, true},
+		},
+		"downsample": {
+			retention: Retention{DownsampleAge: 2 * 24 * time.Hour, DownsamplePeriod: 24 * time.Hour},
+			events: []HistoryEvent{
+				daysAgo(5.5), daysAgo(5.25), // same day, old
+				daysAgo(4.5),                // single event of its day, old
+				daysAgo(1.5), daysAgo(1.25), // same day, recent
+			},
+			keep: []bool{false, true, true, true, true},
+		},
+		"downsample then max events": {
+			retention: Retention{
+				MaxEvents:        2,
+				DownsampleAge:    2 * 24 * time.Hour,
+				DownsamplePeriod: 24 * time.Hour,
+			},
+			events: []HistoryEvent{daysAgo(5.5), daysAgo(5.25), daysAgo(4.5), daysAgo(1)},
+			keep:   []bool{false, false, true, true},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			keep := testCase.retention.Keep(testCase.events, now)
+
+			assert.Equal(t, testCase.keep, keep)
+		})
+	}
+}
+
+func Test_Retention_Prune(t *testing.T) {
+	t.Parallel()
+
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := []HistoryEvent{
+		{Time: t0.Add(time.Hour)},
+		{Time: t0.Add(2 * time.Hour)},
+		{Time: t0.Add(3 * time.Hour)},
+	}
+	retention := Retention{MaxEvents: 1}
+	pruned := HistoryStats{Changes: 2, FirstSeen: t0}
+
+	kept, pruned := retention.Prune(events, t0, pruned)
+
+	assert.Equal(t, events[2:], kept)
+	assert.Equal(t, HistoryStats{Changes: 4, FirstSeen: t0}, pruned)
+	assert.Equal(t, HistoryStats{Changes: 5, FirstSeen: t0}, pruned.Add(kept))
+}
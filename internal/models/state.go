@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// RecordState contains the state of a record persisted
+// across restarts, apart from its IP addresses history.
+type RecordState struct {
+	Status  Status     `json:"status,omitempty"`
+	Message string     `json:"message,omitempty"`
+	Time    time.Time  `json:"time"`
+	LastBan *time.Time `json:"last_ban,omitempty"` // nil means no last ban
+	// ErrorCategory is the category of the last update error,
+	// and is empty if the last update succeeded.
+	ErrorCategory string `json:"error_category,omitempty"`
+	// LastFailure is the time of the last update failure,
+	// and is kept after a successful update.
+	LastFailure *time.Time `json:"last_failure,omitempty"` // nil means no failure
+	LastError   string     `json:"last_error,omitempty"`
+	Backoff     Backoff    `json:"backoff"`
+}
+
+// Equal returns true if both record states are the same.
+func (s RecordState) Equal(other RecordState) bool {
+	return s.Status == other.Status &&
+		s.Message == other.Message &&
+		s.Time.Equal(other.Time) &&
+		timePtrEqual(s.LastBan, other.LastBan) &&
+		s.ErrorCategory == other.ErrorCategory &&
+		timePtrEqual(s.LastFailure, other.LastFailure) &&
+		s.LastError == other.LastError &&
+		s.Backoff.Equal(other.Backoff)
+}
+
+func timePtrEqual(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
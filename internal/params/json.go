@@ -8,6 +8,7 @@ import (
 	"io/fs"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/qdm12/ddns-updater/internal/models"
 	"github.com/qdm12/ddns-updater/internal/settings"
@@ -17,10 +18,13 @@ import (
 )
 
 type commonSettings struct {
+	ID        string `json:"id,omitempty"`
 	Provider  string `json:"provider"`
 	Domain    string `json:"domain"`
 	Host      string `json:"host"`
 	IPVersion string `json:"ip_version"`
+	Period    string `json:"period,omitempty"`
+	Cooldown  string `json:"cooldown,omitempty"`
 	// Retro values for warnings
 	IPMethod *string `json:"ip_method,omitempty"`
 	Delay    *uint64 `json:"delay,omitempty"`
@@ -129,9 +133,30 @@ func extractAllSettings(jsonBytes []byte) (
 		allSettings = append(allSettings, newSettings...)
 	}
 
+	ids := make(map[string]struct{}, len(allSettings))
+	for _, s := range allSettings {
+		err = checkIDUnique(ids, s)
+		if err != nil {
+			return nil, warnings, err
+		}
+	}
+
 	return allSettings, warnings, nil
 }
 
+var errIDDuplicate = errors.New("id is used by multiple records")
+
+// checkIDUnique returns an error if the ID of the settings given
+// is in the ids set, and adds the ID to the set otherwise.
+func checkIDUnique(ids map[string]struct{}, s settings.Settings) (err error) {
+	id := settings.ID(s)
+	if _, ok := ids[id]; ok {
+		return fmt.Errorf("%w: %q for %s", errIDDuplicate, id, s)
+	}
+	ids[id] = struct{}{}
+	return nil
+}
+
 func makeSettingsFromObject(common commonSettings, rawSettings json.RawMessage) (
 	settingsSlice []settings.Settings, warnings []string, err error) {
 	provider := models.Provider(common.Provider)
@@ -159,13 +184,66 @@ func makeSettingsFromObject(common commonSettings, rawSettings json.RawMessage)
 		return nil, nil, err
 	}
 
+	commonSettings, err := parseCommon(common)
+	if err != nil {
+		return nil, warnings, err
+	} else if commonSettings.ID != "" && len(hosts) > 1 {
+		return nil, warnings, fmt.Errorf("%w: id %q for hosts %s",
+			errIDMultipleHosts, commonSettings.ID, common.Host)
+	}
+
+	compacted := bytes.NewBuffer(nil)
+	err = json.Compact(compacted, rawSettings)
+	if err != nil {
+		return nil, warnings, err
+	}
+	commonSettings.Raw = compacted.String()
+
 	settingsSlice = make([]settings.Settings, len(hosts))
 	for i, host := range hosts {
-		settingsSlice[i], err = settings.New(provider, rawSettings, common.Domain,
+		providerSettings, err := settings.New(provider, rawSettings, common.Domain,
 			host, ipVersion)
 		if err != nil {
 			return nil, warnings, err
 		}
+		settingsSlice[i] = settings.WithCommon(providerSettings, commonSettings)
 	}
 	return settingsSlice, warnings, nil
 }
+
+var (
+	errIDMultipleHosts   = errors.New("id cannot be set for multiple hosts")
+	errPeriodNotPositive = errors.New("period must be positive")
+	errCooldownNegative  = errors.New("cooldown cannot be negative")
+)
+
+func parseCommon(common commonSettings) (parsed settings.Common, err error) {
+	if common.ID != "" {
+		err = settings.ValidateID(common.ID)
+		if err != nil {
+			return parsed, err
+		}
+		parsed.ID = common.ID
+	}
+
+	if common.Period != "" {
+		parsed.Period, err = time.ParseDuration(common.Period)
+		if err != nil {
+			return parsed, fmt.Errorf("parsing period: %w", err)
+		} else if parsed.Period <= 0 {
+			return parsed, fmt.Errorf("%w: %s", errPeriodNotPositive, parsed.Period)
+		}
+	}
+
+	if common.Cooldown != "" {
+		cooldown, err := time.ParseDuration(common.Cooldown)
+		if err != nil {
+			return parsed, fmt.Errorf("parsing cooldown: %w", err)
+		} else if cooldown < 0 {
+			return parsed, fmt.Errorf("%w: %s", errCooldownNegative, cooldown)
+		}
+		parsed.Cooldown = &cooldown
+	}
+
+	return parsed, nil
+}
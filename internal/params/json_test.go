@@ -0,0 +1,64 @@
+package params
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseCommon(t *testing.T) {
+	t.Parallel()
+
+	zero := time.Duration(0)
+	hour := time.Hour
+
+	testCases := map[string]struct {
+		common     commonSettings
+		parsed     settings.Common
+		errMessage string
+	}{
+		"empty": {},
+		"all set": {
+			common: commonSettings{ID: "home", Period: "5m", Cooldown: "1h"},
+			parsed: settings.Common{ID: "home", Period: 5 * time.Minute, Cooldown: &hour},
+		},
+		"zero cooldown": {
+			common: commonSettings{Cooldown: "0s"},
+			parsed: settings.Common{Cooldown: &zero},
+		},
+		"malformed period": {
+			common:     commonSettings{Period: "x"},
+			errMessage: `parsing period: time: invalid duration "x"`,
+		},
+		"zero period": {
+			common:     commonSettings{Period: "0s"},
+			errMessage: "period must be positive: 0s",
+		},
+		"malformed cooldown": {
+			common:     commonSettings{Cooldown: "x"},
+			errMessage: `parsing cooldown: time: invalid duration "x"`,
+		},
+		"negative cooldown": {
+			common:     commonSettings{Cooldown: "-1m"},
+			errMessage: "cooldown cannot be negative: -1m0s",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			parsed, err := parseCommon(testCase.common)
+
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.parsed, parsed)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package params
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/qdm12/ddns-updater/internal/settings"
+	"github.com/qdm12/golibs/params"
+)
+
+// ValidateJSONSettings validates the JSON settings from the environment
+// variable CONFIG, or from the file at filePath if CONFIG is not set.
+// Contrary to JSONSettings, it does not write or create any file, and
+// it returns all the errors found instead of stopping at the first one.
+func (r *Reader) ValidateJSONSettings(filePath string) (warnings []string, errs []error) {
+	s, err := r.env.Get("CONFIG", params.CaseSensitiveValue())
+	if err != nil {
+		return nil, []error{fmt.Errorf("%w: for environment variable CONFIG", err)}
+	}
+
+	b := []byte(s)
+	if s == "" {
+		r.logger.Info("validating JSON config from file " + filePath)
+		b, err = r.readFile(filePath)
+		if err != nil {
+			return nil, []error{err}
+		}
+	} else {
+		r.logger.Info("validating JSON config from environment variable CONFIG")
+	}
+
+	return ValidateJSON(b)
+}
+
+// ValidateJSON validates the JSON configuration given, and returns
+// all the errors found instead of stopping at the first one.
+func ValidateJSON(b []byte) (warnings []string, errs []error) {
+	return validateAllSettings(b)
+}
+
+var (
+	ErrKeyUnknown    = errors.New("key is unknown")
+	errSettingsEmpty = errors.New("no setting found")
+)
+
+// SettingError is an error for a single element
+// of the settings array of the JSON configuration.
+type SettingError struct {
+	Index    int
+	Provider models.Provider
+	Err      error
+}
+
+func (e *SettingError) Error() string {
+	return fmt.Sprintf("settings[%d] (%s): %s", e.Index, e.Provider, e.Err)
+}
+
+func (e *SettingError) Unwrap() error {
+	return e.Err
+}
+
+func validateAllSettings(jsonBytes []byte) (warnings []string, errs []error) {
+	rawConfig := struct {
+		Settings []json.RawMessage `json:"settings"`
+	}{}
+	err := json.Unmarshal(jsonBytes, &rawConfig)
+	if err != nil {
+		return nil, []error{fmt.Errorf("%w: %w", errUnmarshalRaw, err)}
+	} else if len(rawConfig.Settings) == 0 {
+		return []string{errSettingsEmpty.Error()}, nil
+	}
+
+	ids := make(map[string]struct{}, len(rawConfig.Settings))
+	for i, rawSettings := range rawConfig.Settings {
+		var common commonSettings
+		err = json.Unmarshal(rawSettings, &common)
+		if err != nil {
+			err = fmt.Errorf("%w: %w", errUnmarshalCommon, err)
+			errs = append(errs, &SettingError{Index: i, Err: err})
+			continue
+		}
+		provider := models.Provider(common.Provider)
+
+		settingsSlice, newWarnings, err := makeSettingsFromObject(common, rawSettings)
+		for _, warning := range newWarnings {
+			warnings = append(warnings, fmt.Sprintf("settings[%d] (%s): %s", i, provider, warning))
+		}
+		if err != nil {
+			errs = append(errs, &SettingError{Index: i, Provider: provider, Err: err})
+		}
+		for _, s := range settingsSlice {
+			err = checkIDUnique(ids, s)
+			if err != nil {
+				errs = append(errs, &SettingError{Index: i, Provider: provider, Err: err})
+			}
+		}
+
+		unknownKeys, err := findUnknownKeys(provider, rawSettings)
+		if err != nil {
+			// unknown provider or malformed object, already reported above
+			continue
+		}
+		for _, key := range unknownKeys {
+			err = fmt.Errorf("%w: %q", ErrKeyUnknown, key)
+			errs = append(errs, &SettingError{Index: i, Provider: provider, Err: err})
+		}
+	}
+
+	return warnings, errs
+}
+
+// findUnknownKeys returns the sorted keys of the JSON object
+// given which are not known for the provider given.
+func findUnknownKeys(provider models.Provider, rawSettings json.RawMessage) (
+	unknownKeys []string, err error) {
+	providerKeys, err := settings.ProviderKeys(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	var object map[string]json.RawMessage
+	err = json.Unmarshal(rawSettings, &object)
+	if err != nil {
+		return nil, err
+	}
+
+	knownKeys := commonKeys()
+	for _, key := range providerKeys {
+		knownKeys[key] = struct{}{}
+	}
+
+	for key := range object {
+		if _, ok := knownKeys[key]; !ok {
+			unknownKeys = append(unknownKeys, key)
+		}
+	}
+	sort.Strings(unknownKeys)
+	return unknownKeys, nil
+}
+
+// commonKeys returns the set of JSON keys common to all providers,
+// extracted from the JSON tags of the commonSettings struct.
+func commonKeys() (keys map[string]struct{}) {
+	commonType := reflect.TypeOf(commonSettings{})
+	keys = make(map[string]struct{}, commonType.NumField())
+	for i := 0; i < commonType.NumField(); i++ {
+		name, _, _ := strings.Cut(commonType.Field(i).Tag.Get("json"), ",")
+		keys[name] = struct{}{}
+	}
+	return keys
+}
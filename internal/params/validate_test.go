@@ -0,0 +1,81 @@
+package params
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validateAllSettings(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		jsonBytes  string
+		warnings   []string
+		errStrings []string
+	}{
+		"malformed JSON": {
+			jsonBytes: `{"settings": [}`,
+			errStrings: []string{"cannot unmarshal raw configuration: " +
+				"invalid character '}' looking for beginning of value"},
+		},
+		"no setting": {
+			jsonBytes: `{}`,
+			warnings:  []string{"no setting found"},
+		},
+		"valid settings": {
+			jsonBytes: `{"settings": [
+				{"provider": "duckdns", "host": "a", "token": "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"},
+				{"provider": "dreamhost", "domain": "example.com", "host": "@", "key": "ABCDEFGHIJ012345",
+				"period": "1h"}
+			]}`,
+		},
+		"id errors": {
+			jsonBytes: `{"settings": [
+				{"id": "home", "provider": "duckdns", "host": "a", "token": "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"},
+				{"id": "home", "provider": "duckdns", "host": "b", "token": "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"},
+				{"id": "other", "provider": "duckdns", "host": "c,d", "token": "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"},
+				{"id": "a/b", "provider": "duckdns", "host": "e", "token": "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"}
+			]}`,
+			errStrings: []string{
+				`settings[1] (duckdns): id is used by multiple records: "home" for ` +
+					`[domain: duckdns.org | host: b | provider: duckdns | ip: ipv4 or ipv6]`,
+				`settings[2] (duckdns): id cannot be set for multiple hosts: id "other" for hosts c,d`,
+				`settings[3] (duckdns): id is malformed: "a/b" must be 1 to 64 letters, digits, '_', '.' or '-'`,
+			},
+		},
+		"all errors reported": {
+			jsonBytes: `{"settings": [
+				{"provider": "unknown", "domain": "example.com", "host": "@"},
+				{"provider": "duckdns", "host": "a", "token": "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa",
+				"tokn": "x", "proxied": true},
+				{"provider": "dreamhost", "domain": "example.com", "host": "@", "key": "ABCDEFGHIJ012345",
+				"period": "-1h"},
+				{"provider": "duckdns", "host": "a", "token": 1}
+			]}`,
+			errStrings: []string{
+				"settings[0] (unknown): unknown provider: unknown",
+				`settings[1] (duckdns): key is unknown: "proxied"`,
+				`settings[1] (duckdns): key is unknown: "tokn"`,
+				"settings[2] (dreamhost): period must be positive: -1h0m0s",
+				"settings[3] (duckdns): json: cannot unmarshal number into Go struct field .token of type string",
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			warnings, errs := validateAllSettings([]byte(testCase.jsonBytes))
+
+			assert.Equal(t, testCase.warnings, warnings)
+			var errStrings []string
+			for _, err := range errs {
+				errStrings = append(errStrings, err.Error())
+			}
+			assert.Equal(t, testCase.errStrings, errStrings)
+		})
+	}
+}
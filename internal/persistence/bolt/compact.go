@@ -0,0 +1,108 @@
+package bolt
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Compact prunes the events of all records according to
+// the retention policy, and returns the result for each record.
+func (db *Database) Compact(now time.Time) (results []models.CompactResult, err error) {
+	err = db.db.Update(func(tx *bolt.Tx) error {
+		records := tx.Bucket(recordsBucket)
+		return records.ForEach(func(id, value []byte) error {
+			if value != nil { // not a bucket
+				return nil
+			}
+			bucket := records.Bucket(id)
+			pruned, err := db.prune(bucket, now)
+			if err != nil {
+				return fmt.Errorf("for record %s: %w", id, err)
+			}
+			events, err := getEvents(bucket)
+			if err != nil {
+				return fmt.Errorf("for record %s: %w", id, err)
+			}
+			prunedStats, err := getPruned(bucket)
+			if err != nil {
+				return fmt.Errorf("for record %s: %w", id, err)
+			}
+			results = append(results, models.CompactResult{
+				ID:     string(id),
+				Kept:   len(events),
+				Pruned: pruned,
+				Stats:  prunedStats.Add(events),
+			})
+			return nil
+		})
+	})
+	return results, err
+}
+
+// prune prunes the events of the record bucket according to the
+// retention policy, and returns the number of events pruned.
+func (db *Database) prune(recordBucket *bolt.Bucket, now time.Time) (pruned int, err error) {
+	if !db.retention.Enabled() {
+		return 0, nil
+	}
+	eventsBucket := recordBucket.Bucket(eventsBucket)
+	if eventsBucket == nil {
+		return 0, nil
+	}
+
+	var keys [][]byte
+	var events []models.HistoryEvent
+	err = eventsBucket.ForEach(func(key, value []byte) error {
+		var event models.HistoryEvent
+		err := json.Unmarshal(value, &event)
+		if err != nil {
+			return err
+		}
+		// copy the key since it is only valid during the iteration
+		keys = append(keys, append([]byte(nil), key...))
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var removed []models.HistoryEvent
+	keep := db.retention.Keep(events, now)
+	for i, key := range keys {
+		if keep[i] {
+			continue
+		}
+		err = eventsBucket.Delete(key)
+		if err != nil {
+			return 0, err
+		}
+		removed = append(removed, events[i])
+	}
+	if len(removed) == 0 {
+		return 0, nil
+	}
+
+	prunedStats, err := getPruned(recordBucket)
+	if err != nil {
+		return 0, err
+	}
+	err = putJSON(recordBucket, prunedKey, prunedStats.Add(removed))
+	if err != nil {
+		return 0, err
+	}
+	return len(removed), nil
+}
+
+func getPruned(recordBucket *bolt.Bucket) (stats models.HistoryStats, err error) {
+	b := recordBucket.Get(prunedKey)
+	if b == nil {
+		return stats, nil
+	}
+	err = json.Unmarshal(b, &stats)
+	return stats, err
+}
@@ -0,0 +1,152 @@
+// Package bolt implements a transactional persistent database
+// stored in a single bbolt file.
+package bolt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Database is a persistent database where each record is a bucket
+// in the records bucket, keyed by the record identifier. A record bucket
+// contains its key, its state and an events bucket with its IP addresses
+// history, keyed by an increasing sequence number, and the statistics
+// of the events pruned according to the retention policy.
+type Database struct {
+	db        *bolt.DB
+	retention models.Retention
+}
+
+//nolint:gochecknoglobals
+var (
+	recordsBucket = []byte("records")
+	eventsBucket  = []byte("events")
+	keyKey        = []byte("key")
+	stateKey      = []byte("state")
+	prunedKey     = []byte("pruned")
+)
+
+// FileName is the name of the database file in the data directory.
+const FileName = "updates.db"
+
+// NewDatabase opens or creates the bbolt file database. The retention
+// policy is applied to the history of a record when a new IP address
+// is stored for it.
+func NewDatabase(dataDir string, retention models.Retention) (*Database, error) {
+	const perms = 0o600
+	const openTimeout = time.Second // in case another program instance uses the file
+	path := filepath.Join(dataDir, FileName)
+	db, err := bolt.Open(path, perms, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(recordsBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("creating records bucket: %w", err)
+	}
+
+	database := &Database{db: db, retention: retention}
+	err = database.Check()
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("%s validation error: %w", path, err)
+	}
+	return database, nil
+}
+
+func (db *Database) Close() error {
+	return db.db.Close()
+}
+
+// WriteTo writes a consistent copy of the database file to w.
+func (db *Database) WriteTo(w io.Writer) (n int64, err error) {
+	err = db.db.View(func(tx *bolt.Tx) error {
+		n, err = tx.WriteTo(w)
+		return err
+	})
+	return n, err
+}
+
+var (
+	ErrIPRecordsMisordered = errors.New("IP records are not ordered correctly by time")
+	ErrIPEmpty             = errors.New("IP is empty")
+	ErrIPTimeEmpty         = errors.New("time of IP is empty")
+)
+
+func (db *Database) Check() error {
+	return db.db.View(func(tx *bolt.Tx) error {
+		records := tx.Bucket(recordsBucket)
+		return records.ForEach(func(id, value []byte) error {
+			if value != nil { // not a bucket
+				return nil
+			}
+			events, err := getEvents(records.Bucket(id))
+			if err != nil {
+				return fmt.Errorf("for record %s: %w", id, err)
+			}
+			var t time.Time
+			for i, event := range events {
+				switch {
+				case event.Time.Before(t):
+					return fmt.Errorf("%w: for record %s", ErrIPRecordsMisordered, id)
+				case event.IP == nil:
+					return fmt.Errorf("%w: IP %d of %d for record %s",
+						ErrIPEmpty, i+1, len(events), id)
+				case event.Time.IsZero():
+					return fmt.Errorf("%w: IP %d of %d for record %s",
+						ErrIPTimeEmpty, i+1, len(events), id)
+				}
+				t = event.Time
+			}
+			return nil
+		})
+	})
+}
+
+// recordKey is the record key as stored in the database.
+type recordKey struct {
+	Provider  string `json:"provider"`
+	Domain    string `json:"domain"`
+	Host      string `json:"host"`
+	IPVersion string `json:"ip_version"`
+}
+
+// recordBucketForWrite returns the bucket of the record with the
+// ID of the key given, creating the bucket if needed.
+func recordBucketForWrite(tx *bolt.Tx, key models.RecordKey) (bucket *bolt.Bucket, err error) {
+	bucket, err = tx.Bucket(recordsBucket).CreateBucketIfNotExists([]byte(key.ID))
+	if err != nil {
+		return nil, fmt.Errorf("creating bucket for record %s: %w", key.ID, err)
+	}
+
+	if bucket.Get(keyKey) != nil {
+		return bucket, nil
+	}
+
+	b, err := json.Marshal(recordKey{
+		Provider:  string(key.Provider),
+		Domain:    key.Domain,
+		Host:      key.Host,
+		IPVersion: key.IPVersion.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = bucket.Put(keyKey, b)
+	if err != nil {
+		return nil, fmt.Errorf("storing key for record %s: %w", key.ID, err)
+	}
+	return bucket, nil
+}
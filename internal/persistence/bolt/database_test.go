@@ -0,0 +1,112 @@
+package bolt
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/constants"
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Database(t *testing.T) {
+	t.Parallel()
+
+	dataDir := t.TempDir()
+	db, err := NewDatabase(dataDir, models.Retention{})
+	require.NoError(t, err)
+
+	key := models.RecordKey{ID: "a", Provider: "test", Domain: "example.com",
+		Host: "@", IPVersion: ipversion.IP4}
+
+	events, err := db.GetEvents(key)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	state, err := db.GetState(key)
+	require.NoError(t, err)
+	assert.Equal(t, models.RecordState{}, state)
+
+	t1 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	err = db.StoreNewIP(key, net.IPv4(1, 2, 3, 4), t1)
+	require.NoError(t, err)
+	err = db.StoreNewIP(key, net.IPv4(5, 6, 7, 8), t2)
+	require.NoError(t, err)
+	expectedState := models.RecordState{
+		Status:      constants.FAIL,
+		Message:     "banned",
+		Time:        t2,
+		LastFailure: &t2,
+		LastError:   "banned",
+		Backoff:     models.Backoff{Failures: 1, RetryTime: t2.Add(time.Minute)},
+	}
+	err = db.StoreState(key, expectedState)
+	require.NoError(t, err)
+
+	// Check the data is persisted
+	err = db.Close()
+	require.NoError(t, err)
+	db, err = NewDatabase(dataDir, models.Retention{})
+	require.NoError(t, err)
+
+	events, err = db.GetEvents(key)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.True(t, events[0].IP.Equal(net.IPv4(1, 2, 3, 4)))
+	assert.True(t, events[0].Time.Equal(t1))
+	assert.True(t, events[1].IP.Equal(net.IPv4(5, 6, 7, 8)))
+	assert.True(t, events[1].Time.Equal(t2))
+	state, err = db.GetState(key)
+	require.NoError(t, err)
+	assert.True(t, expectedState.Equal(state))
+
+	// Import replaces existing records
+	imported := models.PersistedRecord{
+		Key:    key,
+		Events: []models.HistoryEvent{{IP: net.IPv4(9, 9, 9, 9), Time: t1}},
+	}
+	err = db.Import([]models.PersistedRecord{imported})
+	require.NoError(t, err)
+	events, err = db.GetEvents(key)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.True(t, events[0].IP.Equal(net.IPv4(9, 9, 9, 9)))
+	state, err = db.GetState(key)
+	require.NoError(t, err)
+	assert.Equal(t, models.RecordState{}, state)
+
+	err = db.Close()
+	require.NoError(t, err)
+}
+
+func Test_Database_MergeEvents(t *testing.T) {
+	t.Parallel()
+
+	db, err := NewDatabase(t.TempDir(), models.Retention{MaxEvents: 2})
+	require.NoError(t, err)
+	key := models.RecordKey{ID: "a", Domain: "example.com", Host: "@"}
+
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	event := func(hours int) models.HistoryEvent {
+		return models.HistoryEvent{IP: net.IPv4(1, 1, 1, byte(hours)),
+			Time: t0.Add(time.Duration(hours) * time.Hour)}
+	}
+	err = db.StoreNewIP(key, event(2).IP, event(2).Time)
+	require.NoError(t, err)
+
+	added, err := db.MergeEvents(key, []models.HistoryEvent{event(3), event(2), event(1)}, t0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, added)
+
+	events, err := db.GetEvents(key)
+	require.NoError(t, err)
+	assert.Equal(t, []models.HistoryEvent{event(2), event(3)}, events)
+	err = db.Check()
+	require.NoError(t, err)
+
+	err = db.Close()
+	require.NoError(t, err)
+}
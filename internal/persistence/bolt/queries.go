@@ -0,0 +1,198 @@
+package bolt
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+// StoreNewIP stores a new IP address for a certain record.
+func (db *Database) StoreNewIP(key models.RecordKey, ip net.IP, t time.Time) (err error) {
+	return db.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := recordBucketForWrite(tx, key)
+		if err != nil {
+			return err
+		}
+		err = appendEvent(bucket, models.HistoryEvent{IP: ip, Time: t})
+		if err != nil {
+			return err
+		}
+		_, err = db.prune(bucket, t)
+		return err
+	})
+}
+
+// MergeEvents merges the events given into the history of a certain
+// record, ordered by time and skipping duplicate events, and returns
+// the number of events added. The history is then pruned according to
+// the retention policy at the time now.
+func (db *Database) MergeEvents(key models.RecordKey, events []models.HistoryEvent,
+	now time.Time) (added int, err error) {
+	err = db.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := recordBucketForWrite(tx, key)
+		if err != nil {
+			return err
+		}
+		existing, err := getEvents(bucket)
+		if err != nil {
+			return err
+		}
+		var merged []models.HistoryEvent
+		merged, added = models.MergeEvents(existing, events)
+		if added == 0 {
+			return nil
+		}
+
+		// rewrite all the events since they are ordered by sequence number
+		err = bucket.DeleteBucket(eventsBucket)
+		if err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+			return fmt.Errorf("deleting events bucket: %w", err)
+		}
+		for _, event := range merged {
+			err = appendEvent(bucket, event)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = db.prune(bucket, now)
+		return err
+	})
+	return added, err
+}
+
+// GetEvents gets all the IP addresses history for a certain record, in the order
+// from oldest to newest.
+func (db *Database) GetEvents(key models.RecordKey) (events []models.HistoryEvent, err error) {
+	err = db.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(recordsBucket).Bucket([]byte(key.ID))
+		if bucket == nil {
+			return nil
+		}
+		events, err = getEvents(bucket)
+		return err
+	})
+	return events, err
+}
+
+// StoreState stores the state for a certain record.
+func (db *Database) StoreState(key models.RecordKey, state models.RecordState) (err error) {
+	return db.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := recordBucketForWrite(tx, key)
+		if err != nil {
+			return err
+		}
+		return putState(bucket, state)
+	})
+}
+
+// GetState gets the state for a certain record.
+func (db *Database) GetState(key models.RecordKey) (state models.RecordState, err error) {
+	err = db.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(recordsBucket).Bucket([]byte(key.ID))
+		if bucket == nil {
+			return nil
+		}
+		b := bucket.Get(stateKey)
+		if b == nil {
+			return nil
+		}
+		return json.Unmarshal(b, &state)
+	})
+	return state, err
+}
+
+// Migrate does nothing since records are always
+// persisted with their identifier in this database.
+func (db *Database) Migrate([]models.RecordKey) (err error) {
+	return nil
+}
+
+// Import stores the records given in a single transaction,
+// replacing the history and state of existing records.
+func (db *Database) Import(records []models.PersistedRecord) (err error) {
+	return db.db.Update(func(tx *bolt.Tx) error {
+		for _, record := range records {
+			err := tx.Bucket(recordsBucket).DeleteBucket([]byte(record.Key.ID))
+			if err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+				return fmt.Errorf("deleting record %s: %w", record.Key.ID, err)
+			}
+			bucket, err := recordBucketForWrite(tx, record.Key)
+			if err != nil {
+				return err
+			}
+			for _, event := range record.Events {
+				err = appendEvent(bucket, event)
+				if err != nil {
+					return fmt.Errorf("for record %s: %w", record.Key.ID, err)
+				}
+			}
+			err = putState(bucket, record.State)
+			if err != nil {
+				return fmt.Errorf("for record %s: %w", record.Key.ID, err)
+			}
+			if record.Pruned.Changes > 0 {
+				err = putJSON(bucket, prunedKey, record.Pruned)
+				if err != nil {
+					return fmt.Errorf("for record %s: %w", record.Key.ID, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func appendEvent(recordBucket *bolt.Bucket, event models.HistoryEvent) (err error) {
+	events, err := recordBucket.CreateBucketIfNotExists(eventsBucket)
+	if err != nil {
+		return fmt.Errorf("creating events bucket: %w", err)
+	}
+	sequence, err := events.NextSequence()
+	if err != nil {
+		return fmt.Errorf("getting next event sequence: %w", err)
+	}
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	const uint64Size = 8
+	eventKey := make([]byte, uint64Size)
+	binary.BigEndian.PutUint64(eventKey, sequence)
+	return events.Put(eventKey, b)
+}
+
+// getEvents returns the events of the record bucket given,
+// ordered by their sequence number.
+func getEvents(recordBucket *bolt.Bucket) (events []models.HistoryEvent, err error) {
+	bucket := recordBucket.Bucket(eventsBucket)
+	if bucket == nil {
+		return nil, nil
+	}
+	err = bucket.ForEach(func(_, value []byte) error {
+		var event models.HistoryEvent
+		err := json.Unmarshal(value, &event)
+		if err != nil {
+			return err
+		}
+		events = append(events, event)
+		return nil
+	})
+	return events, err
+}
+
+func putState(recordBucket *bolt.Bucket, state models.RecordState) (err error) {
+	return putJSON(recordBucket, stateKey, state)
+}
+
+func putJSON(bucket *bolt.Bucket, key []byte, value interface{}) (err error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(key, b)
+}
@@ -0,0 +1,51 @@
+package json
+
+import (
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+)
+
+// Compact prunes the events of all records according to
+// the retention policy, and returns the result for each record.
+func (db *Database) Compact(now time.Time) (results []models.CompactResult, err error) {
+	db.Lock()
+	defer db.Unlock()
+	results = make([]models.CompactResult, len(db.data.Records))
+	prunedTotal := 0
+	for i := range db.data.Records {
+		pruned := db.prune(i, now)
+		prunedTotal += pruned
+		record := db.data.Records[i]
+		results[i] = models.CompactResult{
+			ID:     record.ID,
+			Kept:   len(record.Events),
+			Pruned: pruned,
+			Stats:  record.stats(),
+		}
+	}
+	if prunedTotal == 0 {
+		return results, nil
+	}
+	return results, db.write()
+}
+
+// prune prunes the events of the record at the index given according
+// to the retention policy, and returns the number of events pruned.
+func (db *Database) prune(index int, now time.Time) (pruned int) {
+	if !db.retention.Enabled() {
+		return 0
+	}
+	record := &db.data.Records[index]
+	var prunedStats models.HistoryStats
+	if record.Pruned != nil {
+		prunedStats = *record.Pruned
+	}
+	eventsCount := len(record.Events)
+	record.Events, prunedStats = db.retention.Prune(record.Events, now, prunedStats)
+	pruned = eventsCount - len(record.Events)
+	if pruned > 0 {
+		record.Pruned = &prunedStats
+	}
+	return pruned
+}
@@ -0,0 +1,51 @@
+package json
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Database_Compact(t *testing.T) {
+	t.Parallel()
+
+	dataDir := t.TempDir()
+	db, err := NewDatabase(dataDir, 0, false, models.Retention{}, &testLogger{})
+	require.NoError(t, err)
+
+	key := models.RecordKey{ID: "a", Domain: "example.com", Host: "@"}
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		err = db.StoreNewIP(key, net.IPv4(1, 1, 1, byte(i)), t0.Add(time.Duration(i)*time.Hour))
+		require.NoError(t, err)
+	}
+
+	retention := models.Retention{MaxEvents: 2}
+	db, err = NewDatabase(dataDir, 0, false, retention, &testLogger{})
+	require.NoError(t, err)
+
+	results, err := db.Compact(t0)
+	require.NoError(t, err)
+	expectedResults := []models.CompactResult{{
+		ID: "a", Kept: 2, Pruned: 2,
+		Stats: models.HistoryStats{Changes: 4, FirstSeen: t0},
+	}}
+	assert.Equal(t, expectedResults, results)
+
+	// Storing a new IP address prunes the history
+	err = db.StoreNewIP(key, net.IPv4(1, 1, 1, 4), t0.Add(4*time.Hour))
+	require.NoError(t, err)
+
+	db, err = NewDatabase(dataDir, 0, false, retention, &testLogger{})
+	require.NoError(t, err)
+	events, err := db.GetEvents(key)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.True(t, events[0].IP.Equal(net.IPv4(1, 1, 1, 3)))
+	assert.True(t, events[1].IP.Equal(net.IPv4(1, 1, 1, 4)))
+	assert.Equal(t, models.HistoryStats{Changes: 5, FirstSeen: t0}, db.data.Records[0].stats())
+}
@@ -4,16 +4,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
-	"github.com/qdm12/golibs/files"
+	"github.com/qdm12/ddns-updater/internal/models"
 )
 
 type Database struct {
 	data        dataModel
 	filepath    string
-	fileManager files.FileManager
+	generations uint
+	retention   models.Retention
+	logger      Logger
 	sync.RWMutex
 }
 
@@ -23,41 +26,108 @@ func (db *Database) Close() error {
 	return nil
 }
 
-// NewDatabase opens or creates the JSON file database.
-func NewDatabase(dataDir string) (*Database, error) {
+// FileName is the name of the database file in the data directory.
+const FileName = "updates.json"
+
+var ErrDatabaseInvalid = errors.New("database file is invalid")
+
+// NewDatabase opens or creates the JSON file database, keeping the given
+// number of previous generations of the file, rotated when the history
+// of a record changes. If the file is invalid and
+// recovery is enabled, the database is recovered from the newest valid
+// generation and the invalid file is kept with a .corrupt suffix.
+// The retention policy is applied to the history of a record when
+// a new IP address is stored for it.
+func NewDatabase(dataDir string, generations uint, recovery bool,
+	retention models.Retention, logger Logger) (*Database, error) {
 	db := Database{
-		filepath:    dataDir + "/updates.json",
-		fileManager: files.NewFileManager(),
-	}
-	exists, err := db.fileManager.FileExists(db.filepath)
-	if err != nil {
-		return nil, err
+		filepath:    dataDir + "/" + FileName,
+		generations: generations,
+		retention:   retention,
+		logger:      logger,
 	}
 
-	if !exists {
-		data, err := json.Marshal(db.data)
-		if err != nil {
-			return nil, err
-		}
-		err = db.fileManager.WriteToFile(db.filepath, data)
-		if err != nil {
-			return nil, err
-		}
+	repaired, err := db.load(db.filepath)
+	switch {
+	case err == nil && repaired:
+		return &db, db.write()
+	case err == nil:
 		return &db, nil
+	case errors.Is(err, os.ErrNotExist):
+		if db.generationExists() && recovery {
+			return &db, db.recover(err)
+		}
+		// create an empty database file
+		db.data = dataModel{}
+		return &db, db.write()
+	case !recovery:
+		return nil, fmt.Errorf("%w: %w", ErrDatabaseInvalid, err)
+	default:
+		return &db, db.recover(err)
 	}
-	data, err := db.fileManager.ReadFile(db.filepath)
+}
+
+// load reads, repairs and checks the data of the file at path,
+// and returns true if the data was repaired.
+func (db *Database) load(path string) (repaired bool, err error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
+	db.data = dataModel{}
 	err = json.Unmarshal(data, &db.data)
 	if err != nil {
-		return nil, err
+		return false, fmt.Errorf("decoding %s: %w", path, err)
 	}
+
+	repairedCount := db.repair()
 	err = db.Check()
 	if err != nil {
-		return nil, fmt.Errorf("%s validation error: %w", db.filepath, err)
+		return false, fmt.Errorf("%s validation error: %w", path, err)
 	}
-	return &db, nil
+
+	if repairedCount > 0 {
+		db.logger.Warn(fmt.Sprintf("repaired the order of IP addresses of %d records in %s",
+			repairedCount, path))
+	}
+	return repairedCount > 0, nil
+}
+
+var ErrNoValidGeneration = errors.New("no valid generation of the database file")
+
+// recover loads the newest valid generation of the database file,
+// and writes it as the database file.
+func (db *Database) recover(loadErr error) (err error) {
+	for i := uint(1); i <= db.generations; i++ {
+		path := generationPath(db.filepath, i)
+		_, err = db.load(path)
+		if errors.Is(err, os.ErrNotExist) {
+			break
+		} else if err != nil {
+			db.logger.Warn("cannot recover from generation " + path + ": " + err.Error())
+			continue
+		}
+
+		db.logger.Warn(loadErr.Error() + "; recovering from generation " + path)
+		if !errors.Is(loadErr, os.ErrNotExist) {
+			corruptPath := db.filepath + ".corrupt"
+			err = os.Rename(db.filepath, corruptPath)
+			if err != nil {
+				return fmt.Errorf("moving invalid database file: %w", err)
+			}
+			db.logger.Warn("invalid database file moved to " + corruptPath)
+		}
+		return db.write()
+	}
+	return fmt.Errorf("%w: %w: %w", ErrDatabaseInvalid, loadErr, ErrNoValidGeneration)
+}
+
+func (db *Database) generationExists() bool {
+	if db.generations == 0 {
+		return false
+	}
+	_, err := os.Stat(generationPath(db.filepath, 1))
+	return err == nil
 }
 
 var (
@@ -95,10 +165,23 @@ func (db *Database) Check() error {
 	return nil
 }
 
+// write writes the database file and keeps its previous content
+// as a generation. It is used for changes of the records history,
+// so generations cover a meaningful period of time.
 func (db *Database) write() error {
+	return db.writeWithGenerations(db.generations)
+}
+
+// writeState writes the database file without rotating generations,
+// since record states change at least twice for each update attempt.
+func (db *Database) writeState() error {
+	return db.writeWithGenerations(0)
+}
+
+func (db *Database) writeWithGenerations(generations uint) error {
 	data, err := json.MarshalIndent(db.data, "", "  ")
 	if err != nil {
 		return err
 	}
-	return db.fileManager.WriteToFile(db.filepath, data)
+	return writeAtomic(db.filepath, data, generations)
 }
@@ -0,0 +1,154 @@
+package json
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLogger struct {
+	warnings []string
+}
+
+func (l *testLogger) Warn(s string) { l.warnings = append(l.warnings, s) }
+
+func Test_Database_generations(t *testing.T) {
+	t.Parallel()
+
+	dataDir := t.TempDir()
+	path := filepath.Join(dataDir, FileName)
+	const generations = 2
+	db, err := NewDatabase(dataDir, generations, true, models.Retention{}, &testLogger{})
+	require.NoError(t, err)
+
+	key := models.RecordKey{ID: "a", Domain: "example.com", Host: "@"}
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		err = db.StoreNewIP(key, net.IPv4(1, 1, 1, byte(i)), t0.Add(time.Duration(i)*time.Hour))
+		require.NoError(t, err)
+	}
+
+	eventsCount := func(path string) int {
+		t.Helper()
+		logger := &testLogger{}
+		db := &Database{logger: logger}
+		_, err := db.load(path)
+		require.NoError(t, err)
+		if len(db.data.Records) == 0 {
+			return 0
+		}
+		return len(db.data.Records[0].Events)
+	}
+
+	// state changes do not rotate generations
+	for i := 0; i < 3; i++ {
+		err = db.StoreState(key, models.RecordState{Status: "updating"})
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 3, eventsCount(path))
+	assert.Equal(t, 2, eventsCount(generationPath(path, 1)))
+	assert.Equal(t, 1, eventsCount(generationPath(path, 2)))
+	_, err = os.Stat(generationPath(path, 3))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	matches, err := filepath.Glob(filepath.Join(dataDir, "*.tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func Test_NewDatabase_recovery(t *testing.T) {
+	t.Parallel()
+
+	const validData = `{"records":[{"id":"a","domain":"example.com","host":"@","ips":[
+{"ip":"1.2.3.4","time":"2020-01-01T00:00:00Z"}]}]}`
+
+	testCases := map[string]struct {
+		data        string // empty for no file
+		generations []string
+		recovery    bool
+		events      int
+		errMessage  string
+		corrupt     bool
+	}{
+		"truncated file recovered": {
+			data:        `{"records":[{"id":"a","dom`,
+			generations: []string{`{"records":[`, validData},
+			recovery:    true,
+			events:      1,
+			corrupt:     true,
+		},
+		"missing file recovered": {
+			generations: []string{validData},
+			recovery:    true,
+			events:      1,
+		},
+		"truncated file without recovery": {
+			data:        `{"records":[`,
+			generations: []string{validData},
+			errMessage: "database file is invalid: decoding %s: " +
+				"unexpected end of JSON input",
+		},
+		"no valid generation": {
+			data:        `{"records":[`,
+			generations: []string{`{`},
+			recovery:    true,
+			errMessage: "database file is invalid: decoding %s: " +
+				"unexpected end of JSON input: no valid generation of the database file",
+		},
+		"misordered events repaired": {
+			data: `{"records":[{"id":"a","domain":"example.com","host":"@","ips":[
+{"ip":"1.2.3.4","time":"2020-01-02T00:00:00Z"},
+{"ip":"1.2.3.5","time":"2020-01-01T00:00:00Z"}]}]}`,
+			events: 2,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dataDir := t.TempDir()
+			path := filepath.Join(dataDir, FileName)
+			if testCase.data != "" {
+				err := os.WriteFile(path, []byte(testCase.data), 0o600)
+				require.NoError(t, err)
+			}
+			for i, data := range testCase.generations {
+				err := os.WriteFile(generationPath(path, uint(i+1)), []byte(data), 0o600)
+				require.NoError(t, err)
+			}
+
+			db, err := NewDatabase(dataDir, uint(len(testCase.generations)),
+				testCase.recovery, models.Retention{}, &testLogger{})
+
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, fmt.Sprintf(testCase.errMessage, path))
+				return
+			}
+			require.NoError(t, err)
+			events, err := db.GetEvents(models.RecordKey{ID: "a"})
+			require.NoError(t, err)
+			require.Len(t, events, testCase.events)
+			for i := 1; i < len(events); i++ {
+				assert.False(t, events[i].Time.Before(events[i-1].Time))
+			}
+
+			// Check the recovered or repaired data is persisted
+			reopened, err := NewDatabase(dataDir, 0, false, models.Retention{}, &testLogger{})
+			require.NoError(t, err)
+			assert.Equal(t, db.data, reopened.data)
+
+			_, err = os.Stat(path + ".corrupt")
+			assert.Equal(t, testCase.corrupt, err == nil)
+		})
+	}
+}
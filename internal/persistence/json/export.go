@@ -0,0 +1,62 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
+)
+
+// Export returns all the records persisted with an identifier.
+// Records persisted before records had an identifier are skipped,
+// and their count is returned as legacy.
+func (db *Database) Export() (records []models.PersistedRecord, legacy int, err error) {
+	db.RLock()
+	defer db.RUnlock()
+	records = make([]models.PersistedRecord, 0, len(db.data.Records))
+	for _, record := range db.data.Records {
+		if record.ID == "" {
+			legacy++
+			continue
+		}
+
+		var ipVersion ipversion.IPVersion
+		if record.IPVersion != "" {
+			ipVersion, err = ipversion.Parse(record.IPVersion)
+			if err != nil {
+				return nil, 0, fmt.Errorf("for record %s: %w", record.ID, err)
+			}
+		}
+
+		persisted := models.PersistedRecord{
+			Key: models.RecordKey{
+				ID:        record.ID,
+				Provider:  models.Provider(record.Provider),
+				Domain:    record.Domain,
+				Host:      record.Host,
+				IPVersion: ipVersion,
+			},
+			Events: append([]models.HistoryEvent(nil), record.Events...),
+			State:  record.state(),
+		}
+		if record.Pruned != nil {
+			persisted.Pruned = *record.Pruned
+		}
+		records = append(records, persisted)
+	}
+	return records, legacy, nil
+}
+
+// WriteTo writes the content of the database file to w.
+func (db *Database) WriteTo(w io.Writer) (n int64, err error) {
+	db.RLock()
+	defer db.RUnlock()
+	data, err := json.MarshalIndent(db.data, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	written, err := w.Write(data)
+	return int64(written), err
+}
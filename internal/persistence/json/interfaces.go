@@ -0,0 +1,5 @@
+package json
+
+type Logger interface {
+	Warn(s string)
+}
@@ -0,0 +1,72 @@
+package json
+
+import (
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
+)
+
+// Migrate migrates the records persisted before records had an
+// identifier, which are only keyed by domain and host, to records for
+// each of the keys given. The history of a legacy record is split by
+// IP version, such that an IPv4 record only gets the IPv4 addresses.
+// Legacy records migrated are removed, and legacy records not matching
+// any of the keys given are kept as they are.
+func (db *Database) Migrate(keys []models.RecordKey) (err error) {
+	db.Lock()
+	defer db.Unlock()
+
+	migrated := make(map[int]struct{})
+	for _, key := range keys {
+		legacyIndex := db.findLegacy(key.Domain, key.Host)
+		if legacyIndex == -1 {
+			continue
+		}
+		migrated[legacyIndex] = struct{}{}
+		if db.find(key) != -1 { // already migrated
+			continue
+		}
+		legacy := db.data.Records[legacyIndex]
+		migratedRecord := newRecord(key)
+		migratedRecord.Events = filterEvents(legacy.Events, key.IPVersion)
+		migratedRecord.Backoff = legacy.Backoff
+		db.data.Records = append(db.data.Records, migratedRecord)
+	}
+
+	if len(migrated) == 0 {
+		return nil
+	}
+
+	records := make([]record, 0, len(db.data.Records)-len(migrated))
+	for i, record := range db.data.Records {
+		if _, ok := migrated[i]; !ok {
+			records = append(records, record)
+		}
+	}
+	db.data.Records = records
+	return db.write()
+}
+
+// findLegacy returns the index of the record without ID matching the
+// domain and host given, or -1 if no record is found.
+func (db *Database) findLegacy(domain, host string) (index int) {
+	for i, record := range db.data.Records {
+		if record.ID == "" && record.Domain == domain && record.Host == host {
+			return i
+		}
+	}
+	return -1
+}
+
+func filterEvents(events []models.HistoryEvent,
+	version ipversion.IPVersion) (filtered []models.HistoryEvent) {
+	for _, event := range events {
+		isIPv4 := event.IP.To4() != nil
+		switch {
+		case version == ipversion.IP4 && !isIPv4,
+			version == ipversion.IP6 && isIPv4:
+			continue
+		}
+		filtered = append(filtered, event)
+	}
+	return filtered
+}
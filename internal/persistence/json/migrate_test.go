@@ -0,0 +1,112 @@
+package json
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Database_Migrate(t *testing.T) {
+	t.Parallel()
+
+	dataDir := t.TempDir()
+	const legacyData = `{"records":[
+{"domain":"example.com","host":"@","ips":[
+{"ip":"1.2.3.4","time":"2020-01-01T00:00:00Z"},
+{"ip":"::1","time":"2020-01-02T00:00:00Z"}]},
+{"domain":"other.com","host":"@","ips":[]}]}`
+	err := os.WriteFile(filepath.Join(dataDir, "updates.json"), []byte(legacyData), 0o600)
+	require.NoError(t, err)
+
+	db, err := NewDatabase(dataDir, 0, false, models.Retention{}, nil)
+	require.NoError(t, err)
+
+	keyIPv4 := models.RecordKey{ID: "a", Provider: "test", Domain: "example.com",
+		Host: "@", IPVersion: ipversion.IP4}
+	keyIPv6 := models.RecordKey{ID: "b", Provider: "test", Domain: "example.com",
+		Host: "@", IPVersion: ipversion.IP6}
+	err = db.Migrate([]models.RecordKey{keyIPv4, keyIPv6})
+	require.NoError(t, err)
+
+	expectedIPv4Events := []models.HistoryEvent{
+		{IP: net.IPv4(1, 2, 3, 4), Time: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	expectedIPv6Events := []models.HistoryEvent{
+		{IP: net.ParseIP("::1"), Time: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	// Check the migration is persisted
+	db, err = NewDatabase(dataDir, 0, false, models.Retention{}, nil)
+	require.NoError(t, err)
+
+	events, err := db.GetEvents(keyIPv4)
+	require.NoError(t, err)
+	assertEventsEqual(t, expectedIPv4Events, events)
+	events, err = db.GetEvents(keyIPv6)
+	require.NoError(t, err)
+	assertEventsEqual(t, expectedIPv6Events, events)
+
+	require.Len(t, db.data.Records, 3)
+	assert.Equal(t, "other.com", db.data.Records[0].Domain)
+	assert.Empty(t, db.data.Records[0].ID)
+	assert.Equal(t, "ipv4", db.data.Records[1].IPVersion)
+	assert.Equal(t, "test", db.data.Records[1].Provider)
+
+	// Migrating again is a no-op
+	err = db.Migrate([]models.RecordKey{keyIPv4, keyIPv6})
+	require.NoError(t, err)
+	require.Len(t, db.data.Records, 3)
+}
+
+func assertEventsEqual(t *testing.T, expected, actual []models.HistoryEvent) {
+	t.Helper()
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		assert.True(t, expected[i].IP.Equal(actual[i].IP))
+		assert.True(t, expected[i].Time.Equal(actual[i].Time))
+	}
+}
+
+func Test_filterEvents(t *testing.T) {
+	t.Parallel()
+
+	ipv4Event := models.HistoryEvent{IP: net.IPv4(1, 2, 3, 4)}
+	ipv6Event := models.HistoryEvent{IP: net.ParseIP("::1")}
+	events := []models.HistoryEvent{ipv4Event, ipv6Event}
+
+	testCases := map[string]struct {
+		version  ipversion.IPVersion
+		filtered []models.HistoryEvent
+	}{
+		"ipv4": {
+			version:  ipversion.IP4,
+			filtered: []models.HistoryEvent{ipv4Event},
+		},
+		"ipv6": {
+			version:  ipversion.IP6,
+			filtered: []models.HistoryEvent{ipv6Event},
+		},
+		"ipv4 or ipv6": {
+			version:  ipversion.IP4or6,
+			filtered: events,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filtered := filterEvents(events, testCase.version)
+
+			assert.Equal(t, testCase.filtered, filtered)
+		})
+	}
+}
@@ -11,9 +11,43 @@ type dataModel struct {
 }
 
 type record struct {
-	Domain string                `json:"domain"`
-	Host   string                `json:"host"`
-	Events []models.HistoryEvent `json:"ips"`
+	// ID is the record identifier, and is empty for
+	// records persisted before records had an identifier.
+	ID        string                `json:"id,omitempty"`
+	Provider  string                `json:"provider,omitempty"`
+	Domain    string                `json:"domain"`
+	Host      string                `json:"host"`
+	IPVersion string                `json:"ip_version,omitempty"`
+	Events    []models.HistoryEvent `json:"ips"`
+	State     *models.RecordState   `json:"state,omitempty"`
+	// Pruned contains the statistics of the events pruned
+	// according to the retention policy.
+	Pruned *models.HistoryStats `json:"pruned,omitempty"`
+	// Backoff is the backoff state of records persisted
+	// before their state was persisted.
+	Backoff *models.Backoff `json:"backoff,omitempty"`
+}
+
+// state returns the state of the record. For a record persisted
+// before record states were persisted, only its backoff state is set.
+func (r record) state() (state models.RecordState) {
+	switch {
+	case r.State != nil:
+		return *r.State
+	case r.Backoff != nil:
+		state.Backoff = *r.Backoff
+	}
+	return state
+}
+
+// stats returns the statistics of all the events of the
+// record, including the events pruned.
+func (r record) stats() models.HistoryStats {
+	var stats models.HistoryStats
+	if r.Pruned != nil {
+		stats = *r.Pruned
+	}
+	return stats.Add(r.Events)
 }
 
 func (r record) String() string {
@@ -7,39 +7,97 @@ import (
 	"github.com/qdm12/ddns-updater/internal/models"
 )
 
-// StoreNewIP stores a new IP address for a certain domain and host.
-func (db *Database) StoreNewIP(domain, host string, ip net.IP, t time.Time) (err error) {
+// StoreNewIP stores a new IP address for a certain record.
+func (db *Database) StoreNewIP(key models.RecordKey, ip net.IP, t time.Time) (err error) {
 	db.Lock()
 	defer db.Unlock()
-	for i, record := range db.data.Records {
-		if record.Domain == domain && record.Host == host {
-			db.data.Records[i].Events = append(db.data.Records[i].Events, models.HistoryEvent{
-				IP:   ip,
-				Time: t,
-			})
-			return db.write()
-		}
-	}
-	db.data.Records = append(db.data.Records, record{
-		Domain: domain,
-		Host:   host,
-		Events: []models.HistoryEvent{{
-			IP:   ip,
-			Time: t,
-		}},
+	i := db.indexForWrite(key)
+	db.data.Records[i].Events = append(db.data.Records[i].Events, models.HistoryEvent{
+		IP:   ip,
+		Time: t,
 	})
+	db.prune(i, t)
 	return db.write()
 }
 
-// GetEvents gets all the IP addresses history for a certain domain and host, in the order
+// MergeEvents merges the events given into the history of a certain
+// record, ordered by time and skipping duplicate events, and returns
+// the number of events added. The history is then pruned according to
+// the retention policy at the time now.
+func (db *Database) MergeEvents(key models.RecordKey, events []models.HistoryEvent,
+	now time.Time) (added int, err error) {
+	db.Lock()
+	defer db.Unlock()
+	i := db.indexForWrite(key)
+	db.data.Records[i].Events, added = models.MergeEvents(db.data.Records[i].Events, events)
+	if added == 0 {
+		return 0, nil
+	}
+	db.prune(i, now)
+	return added, db.write()
+}
+
+// GetEvents gets all the IP addresses history for a certain record, in the order
 // from oldest to newest.
-func (db *Database) GetEvents(domain, host string) (events []models.HistoryEvent, err error) {
+func (db *Database) GetEvents(key models.RecordKey) (events []models.HistoryEvent, err error) {
 	db.RLock()
 	defer db.RUnlock()
-	for _, record := range db.data.Records {
-		if record.Domain == domain && record.Host == host {
-			return append(events, record.Events...), nil
+	i := db.find(key)
+	if i == -1 {
+		return nil, nil
+	}
+	return append(events, db.data.Records[i].Events...), nil
+}
+
+// StoreState stores the state for a certain record.
+func (db *Database) StoreState(key models.RecordKey, state models.RecordState) (err error) {
+	db.Lock()
+	defer db.Unlock()
+	i := db.indexForWrite(key)
+	db.data.Records[i].State = &state
+	db.data.Records[i].Backoff = nil // superseded by the state backoff
+	return db.writeState()
+}
+
+// GetState gets the state for a certain record.
+func (db *Database) GetState(key models.RecordKey) (state models.RecordState, err error) {
+	db.RLock()
+	defer db.RUnlock()
+	i := db.find(key)
+	if i == -1 {
+		return state, nil
+	}
+	return db.data.Records[i].state(), nil
+}
+
+// find returns the index of the record with the ID of the
+// key given, or -1 if no record is found.
+func (db *Database) find(key models.RecordKey) (index int) {
+	for i, record := range db.data.Records {
+		if record.ID == key.ID {
+			return i
 		}
 	}
-	return nil, nil
+	return -1
+}
+
+// indexForWrite returns the index of the record with the
+// ID of the key given, creating the record if needed.
+func (db *Database) indexForWrite(key models.RecordKey) (index int) {
+	index = db.find(key)
+	if index != -1 {
+		return index
+	}
+	db.data.Records = append(db.data.Records, newRecord(key))
+	return len(db.data.Records) - 1
+}
+
+func newRecord(key models.RecordKey) record {
+	return record{
+		ID:        key.ID,
+		Provider:  string(key.Provider),
+		Domain:    key.Domain,
+		Host:      key.Host,
+		IPVersion: key.IPVersion.String(),
+	}
 }
@@ -0,0 +1,99 @@
+package json
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/constants"
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Database_State(t *testing.T) {
+	t.Parallel()
+
+	dataDir := t.TempDir()
+	const data = `{"records":[
+{"id":"a","domain":"example.com","host":"@","ips":[],
+"backoff":{"failures":2,"retry_time":"2020-01-01T00:00:00Z"}}]}`
+	err := os.WriteFile(filepath.Join(dataDir, "updates.json"), []byte(data), 0o600)
+	require.NoError(t, err)
+
+	db, err := NewDatabase(dataDir, 0, false, models.Retention{}, nil)
+	require.NoError(t, err)
+	key := models.RecordKey{ID: "a", Domain: "example.com", Host: "@"}
+
+	// Backoff persisted before the record state
+	state, err := db.GetState(key)
+	require.NoError(t, err)
+	expectedState := models.RecordState{
+		Backoff: models.Backoff{
+			Failures:  2,
+			RetryTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	assert.True(t, expectedState.Equal(state))
+
+	lastBan := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	expectedState = models.RecordState{
+		Status:        constants.FAIL,
+		Message:       "banned",
+		Time:          lastBan,
+		LastBan:       &lastBan,
+		ErrorCategory: "rate limited",
+		LastFailure:   &lastBan,
+		LastError:     "banned",
+		Backoff:       models.Backoff{Failures: 3, RetryTime: lastBan.Add(time.Hour)},
+	}
+	err = db.StoreState(key, expectedState)
+	require.NoError(t, err)
+
+	db, err = NewDatabase(dataDir, 0, false, models.Retention{}, nil)
+	require.NoError(t, err)
+	state, err = db.GetState(key)
+	require.NoError(t, err)
+	assert.True(t, expectedState.Equal(state))
+	assert.Nil(t, db.data.Records[0].Backoff)
+
+	state, err = db.GetState(models.RecordKey{ID: "b"})
+	require.NoError(t, err)
+	assert.Equal(t, models.RecordState{}, state)
+}
+
+func Test_Database_MergeEvents(t *testing.T) {
+	t.Parallel()
+
+	dataDir := t.TempDir()
+	db, err := NewDatabase(dataDir, 0, false, models.Retention{}, nil)
+	require.NoError(t, err)
+	key := models.RecordKey{ID: "a", Domain: "example.com", Host: "@"}
+
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	event := func(hours int) models.HistoryEvent {
+		return models.HistoryEvent{IP: net.IPv4(1, 1, 1, byte(hours)),
+			Time: t0.Add(time.Duration(hours) * time.Hour)}
+	}
+	err = db.StoreNewIP(key, event(2).IP, event(2).Time)
+	require.NoError(t, err)
+
+	added, err := db.MergeEvents(key, []models.HistoryEvent{event(3), event(2), event(1)}, t0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, added)
+
+	// Check the merged events are persisted
+	db, err = NewDatabase(dataDir, 0, false, models.Retention{}, nil)
+	require.NoError(t, err)
+	events, err := db.GetEvents(key)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	for i, expected := range []models.HistoryEvent{event(1), event(2), event(3)} {
+		assert.True(t, expected.IP.Equal(events[i].IP))
+		assert.True(t, expected.Time.Equal(events[i].Time))
+	}
+	err = db.Check()
+	require.NoError(t, err)
+}
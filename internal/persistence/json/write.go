@@ -0,0 +1,144 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// writeAtomic writes data to a temporary file synced to disk and
+// renames it to path, such that path is never partially written.
+// The previous content of path is kept as generation 1, and older
+// generations are shifted up to the number of generations given.
+func writeAtomic(path string, data []byte, generations uint) (err error) {
+	const dirPerms = 0o700
+	dir := filepath.Dir(path)
+	err = os.MkdirAll(dir, dirPerms)
+	if err != nil {
+		return err
+	}
+
+	tempFile, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := tempFile.Name()
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tempPath)
+		}
+	}()
+
+	_, err = tempFile.Write(data)
+	if err == nil {
+		err = tempFile.Sync()
+	}
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("writing temporary file: %w", err)
+	}
+
+	err = rotateGenerations(path, generations)
+	if err != nil {
+		return fmt.Errorf("rotating generations: %w", err)
+	}
+
+	err = os.Rename(tempPath, path)
+	if err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// rotateGenerations shifts the existing generations of the file at path,
+// and keeps its current content as generation 1. It does nothing if
+// the number of generations is zero or if the file does not exist.
+func rotateGenerations(path string, generations uint) (err error) {
+	if generations == 0 {
+		return nil
+	}
+	_, err = os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for i := generations; i > 1; i-- {
+		err = os.Rename(generationPath(path, i-1), generationPath(path, i))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	firstPath := generationPath(path, 1)
+	err = os.Remove(firstPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	err = os.Link(path, firstPath)
+	if err == nil {
+		return nil
+	}
+	// hard links may not be supported by the file system
+	return copyFile(path, firstPath)
+}
+
+func copyFile(sourcePath, destinationPath string) (err error) {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	const perms = 0o600
+	destination, err := os.OpenFile(destinationPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perms)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(destination, source)
+	if err == nil {
+		err = destination.Sync()
+	}
+	if closeErr := destination.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// syncDir syncs the directory to persist a rename, ignoring
+// errors since not all platforms support syncing directories.
+func syncDir(dir string) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = f.Sync()
+	_ = f.Close()
+}
+
+func generationPath(path string, generation uint) string {
+	return path + "." + strconv.FormatUint(uint64(generation), 10)
+}
+
+// repair sorts the IP addresses of each record by time, and
+// returns the number of records which were not sorted.
+func (db *Database) repair() (repaired int) {
+	for _, record := range db.data.Records {
+		events := record.Events
+		less := func(i, j int) bool { return events[i].Time.Before(events[j].Time) }
+		if sort.SliceIsSorted(events, less) {
+			continue
+		}
+		sort.SliceStable(events, less)
+		repaired++
+	}
+	return repaired
+}
@@ -12,6 +12,7 @@ import (
 func (r *Record) HTML(now time.Time) models.HTMLRow {
 	const NotAvailable = "N/A"
 	row := r.Settings.HTML()
+	row.ID = models.HTML(r.ID)
 	message := r.Message
 	if r.Status == constants.UPTODATE {
 		message = "no IP change for " + r.History.GetDurationSinceSuccess(now)
@@ -26,6 +27,16 @@ func (r *Record) HTML(now time.Time) models.HTMLRow {
 			convertStatus(r.Status),
 			message,
 			time.Since(r.Time).Round(time.Second).String()+" ago"))
+		if r.ErrorCategory != "" {
+			row.Status += models.HTML("<br>" + string(r.ErrorCategory) + " error")
+		}
+		if r.Backoff.Failures > 0 {
+			row.Status += models.HTML(backoffHTML(r.Backoff, now))
+		}
+		if r.LastFailure != nil && r.Status != constants.FAIL && r.Status != constants.DISABLED {
+			row.Status += models.HTML("<br>last failure " +
+				now.Sub(*r.LastFailure).Round(time.Second).String() + " ago: " + r.LastError)
+		}
 	}
 	currentIP := r.History.GetCurrentIP()
 	if currentIP != nil {
@@ -50,6 +61,14 @@ func (r *Record) HTML(now time.Time) models.HTMLRow {
 	return row
 }
 
+func backoffHTML(backoff models.Backoff, now time.Time) string {
+	s := fmt.Sprintf("<br>%d consecutive failures", backoff.Failures)
+	if backoff.IsWithin(now) {
+		s += ", retrying in " + backoff.RetryTime.Sub(now).Round(time.Second).String()
+	}
+	return s
+}
+
 func convertStatus(status models.Status) models.HTML {
 	switch status {
 	case constants.SUCCESS:
@@ -62,6 +81,8 @@ func convertStatus(status models.Status) models.HTML {
 		return `<font color="orange"><b>Updating</b></font>`
 	case constants.UNSET:
 		return `<font color="purple"><b>Unset</b></font>`
+	case constants.DISABLED:
+		return `<font color="grey"><b>Disabled</b></font>`
 	default:
 		return "Unknown status"
 	}
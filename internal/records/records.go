@@ -7,24 +7,69 @@ import (
 	"github.com/qdm12/ddns-updater/internal/constants"
 	"github.com/qdm12/ddns-updater/internal/models"
 	"github.com/qdm12/ddns-updater/internal/settings"
+	settingserrors "github.com/qdm12/ddns-updater/internal/settings/errors"
 )
 
 // Record contains all the information to update and display a DNS record.
 type Record struct { // internal
+	// ID is the stable identifier of the record, see settings.ID.
+	ID       string
 	Settings settings.Settings // fixed
 	History  models.History    // past information
 	Status   models.Status
 	Message  string
 	Time     time.Time
 	LastBan  *time.Time // nil means no last ban
+	Backoff  models.Backoff
+	// ErrorCategory is the category of the last update error,
+	// and is empty if the last update succeeded.
+	ErrorCategory settingserrors.Category
+	// LastFailure is the time of the last update failure, and is
+	// kept after a successful update together with LastError.
+	LastFailure *time.Time // nil means no failure
+	LastError   string
 }
 
-// New returns a new Record with settings and some history.
-func New(settings settings.Settings, events []models.HistoryEvent) Record {
+// New returns a new Record with settings, some history and its
+// persisted state. A record restored while updating is unset since its
+// update was interrupted, and a record restored disabled is failed since
+// records are only disabled until the program is restarted.
+func New(s settings.Settings, events []models.HistoryEvent,
+	state models.RecordState) Record {
+	status := state.Status
+	switch {
+	case status == constants.DISABLED,
+		status == "" && state.Backoff.Failures > 0: // backoff persisted without state
+		status = constants.FAIL
+	case status == "", status == constants.UPDATING:
+		status = constants.UNSET
+	}
 	return Record{
-		Settings: settings,
-		History:  events,
-		Status:   constants.UNSET,
+		ID:            settings.ID(s),
+		Settings:      s,
+		History:       events,
+		Status:        status,
+		Message:       state.Message,
+		Time:          state.Time,
+		LastBan:       state.LastBan,
+		Backoff:       state.Backoff,
+		ErrorCategory: settingserrors.Category(state.ErrorCategory),
+		LastFailure:   state.LastFailure,
+		LastError:     state.LastError,
+	}
+}
+
+// State returns the state of the record to persist.
+func (r *Record) State() models.RecordState {
+	return models.RecordState{
+		Status:        r.Status,
+		Message:       r.Message,
+		Time:          r.Time,
+		LastBan:       r.LastBan,
+		ErrorCategory: string(r.ErrorCategory),
+		LastFailure:   r.LastFailure,
+		LastError:     r.LastError,
+		Backoff:       r.Backoff,
 	}
 }
 
@@ -33,6 +78,13 @@ func (r *Record) String() string {
 	if r.Message != "" {
 		status += " (" + r.Message + ")"
 	}
-	return fmt.Sprintf("%s: %s %s; %s",
-		r.Settings, status, r.Time.Format("2006-01-02 15:04:05 MST"), r.History)
+	if r.ErrorCategory != "" {
+		status += " {" + string(r.ErrorCategory) + " error}"
+	}
+	if r.Backoff.Failures > 0 {
+		status += fmt.Sprintf(" [%d failures, retry at %s]", r.Backoff.Failures,
+			r.Backoff.RetryTime.Format("2006-01-02 15:04:05 MST"))
+	}
+	return fmt.Sprintf("%s (id %s): %s %s; %s",
+		r.Settings, r.ID, status, r.Time.Format("2006-01-02 15:04:05 MST"), r.History)
 }
@@ -0,0 +1,80 @@
+package records
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/constants"
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/qdm12/ddns-updater/internal/settings"
+	"github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSettings struct {
+	settings.Settings
+}
+
+func (s *testSettings) Provider() models.Provider      { return "test" }
+func (s *testSettings) Domain() string                 { return "example.com" }
+func (s *testSettings) Host() string                   { return "@" }
+func (s *testSettings) IPVersion() ipversion.IPVersion { return ipversion.IP4 }
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+
+	lastBan := time.Unix(1, 0)
+	lastFailure := time.Unix(2, 0)
+
+	testCases := map[string]struct {
+		state  models.RecordState
+		status models.Status
+	}{
+		"no state": {
+			status: constants.UNSET,
+		},
+		"backoff only": {
+			state:  models.RecordState{Backoff: models.Backoff{Failures: 1}},
+			status: constants.FAIL,
+		},
+		"up to date": {
+			state:  models.RecordState{Status: constants.UPTODATE},
+			status: constants.UPTODATE,
+		},
+		"updating": {
+			state:  models.RecordState{Status: constants.UPDATING},
+			status: constants.UNSET,
+		},
+		"disabled": {
+			state:  models.RecordState{Status: constants.DISABLED},
+			status: constants.FAIL,
+		},
+		"failure": {
+			state: models.RecordState{
+				Status:        constants.FAIL,
+				Message:       "banned",
+				Time:          time.Unix(3, 0),
+				LastBan:       &lastBan,
+				ErrorCategory: "rate limited",
+				LastFailure:   &lastFailure,
+				LastError:     "banned",
+				Backoff:       models.Backoff{Failures: 2},
+			},
+			status: constants.FAIL,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			record := New(&testSettings{}, nil, testCase.state)
+
+			assert.Equal(t, testCase.status, record.Status)
+			expectedState := testCase.state
+			expectedState.Status = testCase.status
+			assert.True(t, expectedState.Equal(record.State()))
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package reload
+
+import (
+	"context"
+
+	"github.com/qdm12/ddns-updater/internal/settings"
+)
+
+type SettingsReader interface {
+	JSONSettings(filePath string) (allSettings []settings.Settings, warnings []string, err error)
+}
+
+type Reloader interface {
+	Reload(ctx context.Context, allSettings []settings.Settings) (err error)
+}
+
+type Logger interface {
+	Info(s string)
+	Warn(s string)
+	Error(s string)
+}
@@ -0,0 +1,103 @@
+// Package reload reloads the records settings when the JSON
+// configuration file changes or when a signal is received.
+package reload
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"time"
+)
+
+type Watcher struct {
+	filePath string
+	period   time.Duration
+	fromEnv  bool
+	signals  <-chan os.Signal
+	reader   SettingsReader
+	reloader Reloader
+	notify   func(message string)
+	logger   Logger
+	readFile func(filename string) ([]byte, error)
+}
+
+// NewWatcher creates a watcher reloading the settings from the file
+// at filePath when its content changes, checking it every period, or
+// when a signal is received on the signals channel. A zero period
+// disables checking the file. If fromEnv is true, the settings are set
+// in an environment variable and written to the file on each reload,
+// so the file is not checked since edits to it would be overwritten.
+func NewWatcher(filePath string, period time.Duration, fromEnv bool, signals <-chan os.Signal,
+	reader SettingsReader, reloader Reloader, notify func(message string),
+	logger Logger) *Watcher {
+	return &Watcher{
+		filePath: filePath,
+		period:   period,
+		fromEnv:  fromEnv,
+		signals:  signals,
+		reader:   reader,
+		reloader: reloader,
+		notify:   notify,
+		logger:   logger,
+		readFile: os.ReadFile,
+	}
+}
+
+func (w *Watcher) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	content, _ := w.readFile(w.filePath)
+
+	var tick <-chan time.Time
+	switch {
+	case w.period > 0 && w.fromEnv:
+		w.logger.Warn("not watching " + w.filePath + " for changes since settings are " +
+			"set with the CONFIG environment variable, and edits to the file are ignored")
+	case w.period > 0:
+		ticker := time.NewTicker(w.period)
+		defer ticker.Stop()
+		tick = ticker.C
+		w.logger.Info("watching " + w.filePath + " for changes every " + w.period.String())
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case signal := <-w.signals:
+			w.logger.Info("received " + signal.String() + " signal, reloading settings")
+			w.reload(ctx)
+		case <-tick:
+			newContent, err := w.readFile(w.filePath)
+			if err != nil || bytes.Equal(newContent, content) {
+				continue
+			}
+			w.logger.Info(w.filePath + " changed, reloading settings")
+			w.reload(ctx)
+		}
+		// The settings reader may rewrite the file, for example
+		// if the settings are set in an environment variable.
+		content, _ = w.readFile(w.filePath)
+	}
+}
+
+func (w *Watcher) reload(ctx context.Context) {
+	allSettings, warnings, err := w.reader.JSONSettings(w.filePath)
+	for _, warning := range warnings {
+		w.logger.Warn(warning)
+		w.notify(warning)
+	}
+	if err != nil {
+		message := "reloading settings failed, keeping current settings: " + err.Error()
+		w.logger.Error(message)
+		w.notify(message)
+		return
+	}
+
+	err = w.reloader.Reload(ctx, allSettings)
+	if err != nil {
+		message := "reloading records failed: " + err.Error()
+		w.logger.Error(message)
+		w.notify(message)
+	}
+}
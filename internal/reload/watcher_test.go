@@ -0,0 +1,102 @@
+package reload
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+type testReader struct {
+	mutex sync.Mutex
+	reads int
+}
+
+func (r *testReader) JSONSettings(string) (allSettings []settings.Settings,
+	warnings []string, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.reads++
+	return nil, nil, nil
+}
+
+func (r *testReader) count() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.reads
+}
+
+type testReloader struct{}
+
+func (testReloader) Reload(context.Context, []settings.Settings) (err error) { return nil }
+
+type testLogger struct {
+	mutex    sync.Mutex
+	warnings []string
+}
+
+func (*testLogger) Info(string)  {}
+func (*testLogger) Error(string) {}
+func (l *testLogger) Warn(s string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.warnings = append(l.warnings, s)
+}
+
+func Test_Watcher_Run(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		fromEnv  bool
+		reloaded bool
+		warnings []string
+	}{
+		"file changed": {
+			reloaded: true,
+		},
+		"file changed with settings from environment": {
+			fromEnv: true,
+			warnings: []string{"not watching config.json for changes since settings " +
+				"are set with the CONFIG environment variable, and edits to the file are ignored"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			reader := &testReader{}
+			logger := &testLogger{}
+			watcher := NewWatcher("config.json", time.Millisecond, testCase.fromEnv,
+				make(chan os.Signal), reader, testReloader{}, func(string) {}, logger)
+			// the file content read first at start differs from later reads
+			var readOnce sync.Once
+			watcher.readFile = func(string) ([]byte, error) {
+				content := []byte("b")
+				readOnce.Do(func() { content = []byte("a") })
+				return content, nil
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go watcher.Run(ctx, done)
+
+			if testCase.reloaded {
+				assert.Eventually(t, func() bool { return reader.count() == 1 },
+					time.Second, time.Millisecond)
+			} else {
+				time.Sleep(20 * time.Millisecond)
+				assert.Zero(t, reader.count())
+			}
+			cancel()
+			<-done
+
+			assert.Equal(t, testCase.warnings, logger.warnings)
+		})
+	}
+}
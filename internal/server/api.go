@@ -0,0 +1,153 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/qdm12/ddns-updater/internal/data"
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/qdm12/ddns-updater/internal/records"
+	"github.com/qdm12/ddns-updater/internal/settings"
+)
+
+// apiRecord is a record as returned by the JSON API,
+// without the secrets of its settings.
+type apiRecord struct {
+	ID       string        `json:"id"`
+	Settings apiSettings   `json:"settings"`
+	Status   models.Status `json:"status"`
+	Message  string        `json:"message,omitempty"`
+	// Time is the time of the last status change.
+	Time          time.Time  `json:"time"`
+	CurrentIP     net.IP     `json:"current_ip,omitempty"`
+	LastBan       *time.Time `json:"last_ban,omitempty"`
+	ErrorCategory string     `json:"error_category,omitempty"`
+	LastFailure   *time.Time `json:"last_failure,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	// Failures is the number of consecutive update failures.
+	Failures  uint       `json:"failures"`
+	RetryTime *time.Time `json:"retry_time,omitempty"`
+}
+
+type apiSettings struct {
+	Provider  models.Provider `json:"provider"`
+	Domain    string          `json:"domain"`
+	Host      string          `json:"host"`
+	FQDN      string          `json:"fqdn"`
+	IPVersion string          `json:"ip_version"`
+	Proxied   bool            `json:"proxied"`
+	// Period and Cooldown are empty if they are not set
+	// for the record, and the program wide defaults apply.
+	Period   string `json:"period,omitempty"`
+	Cooldown string `json:"cooldown,omitempty"`
+}
+
+func makeAPIRecord(record records.Record) apiRecord {
+	common := settings.GetCommon(record.Settings)
+	apiRecord := apiRecord{
+		ID: record.ID,
+		Settings: apiSettings{
+			Provider:  record.Settings.Provider(),
+			Domain:    record.Settings.Domain(),
+			Host:      record.Settings.Host(),
+			FQDN:      record.Settings.BuildDomainName(),
+			IPVersion: record.Settings.IPVersion().String(),
+			Proxied:   record.Settings.Proxied(),
+		},
+		Status:        record.Status,
+		Message:       record.Message,
+		Time:          record.Time,
+		CurrentIP:     record.History.GetCurrentIP(),
+		LastBan:       record.LastBan,
+		ErrorCategory: string(record.ErrorCategory),
+		LastFailure:   record.LastFailure,
+		LastError:     record.LastError,
+		Failures:      record.Backoff.Failures,
+	}
+	if common.Period > 0 {
+		apiRecord.Settings.Period = common.Period.String()
+	}
+	if common.Cooldown != nil {
+		apiRecord.Settings.Cooldown = common.Cooldown.String()
+	}
+	if !record.Backoff.RetryTime.IsZero() {
+		retryTime := record.Backoff.RetryTime
+		apiRecord.RetryTime = &retryTime
+	}
+	return apiRecord
+}
+
+func (h *handlers) apiRecords(w http.ResponseWriter, _ *http.Request) {
+	allRecords := h.db.SelectAll()
+	apiRecords := make([]apiRecord, len(allRecords))
+	for i, record := range allRecords {
+		apiRecords[i] = makeAPIRecord(record)
+	}
+	encodeJSON(w, apiRecords)
+}
+
+func (h *handlers) apiRecord(w http.ResponseWriter, r *http.Request) {
+	record, ok := h.selectRecord(w, r)
+	if !ok {
+		return
+	}
+	encodeJSON(w, makeAPIRecord(record))
+}
+
+type apiHistory struct {
+	ID string `json:"id"`
+	// Events are the IP address changes of
+	// the record, ordered from oldest to newest.
+	Events []models.HistoryEvent `json:"events"`
+}
+
+func (h *handlers) apiRecordHistory(w http.ResponseWriter, r *http.Request) {
+	record, ok := h.selectRecord(w, r)
+	if !ok {
+		return
+	}
+	history := apiHistory{
+		ID:     record.ID,
+		Events: append([]models.HistoryEvent{}, record.History...),
+	}
+	encodeJSON(w, history)
+}
+
+// selectRecord returns the record with the id URL parameter, and writes
+// an error response and returns false if the record is not found.
+func (h *handlers) selectRecord(w http.ResponseWriter, r *http.Request) (
+	record records.Record, ok bool) {
+	record, err := h.db.Select(chi.URLParam(r, "id"))
+	switch {
+	case errors.Is(err, data.ErrRecordNotFound):
+		httpError(w, http.StatusNotFound, err.Error())
+		return record, false
+	case err != nil:
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return record, false
+	}
+	return record, true
+}
+
+func (h *handlers) apiPublicIPs(w http.ResponseWriter, _ *http.Request) {
+	encodeJSON(w, h.runner.State().PublicIPs)
+}
+
+func (h *handlers) apiRunnerState(w http.ResponseWriter, _ *http.Request) {
+	encodeJSON(w, h.runner.State())
+}
+
+func encodeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(v)
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qdm12/ddns-updater/internal/data"
+	"github.com/qdm12/ddns-updater/internal/jobs"
+	"github.com/qdm12/ddns-updater/internal/records"
+	"github.com/qdm12/ddns-updater/internal/update"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_handlers_apiRecords(t *testing.T) {
+	t.Parallel()
+
+	db := &testDatabase{records: []records.Record{
+		newTestRecord(t, "a"),
+		newTestRecord(t, "b"),
+	}}
+	handler := newHandler(context.Background(), "", db, &testRunner{}, nil, nil, false)
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/records", nil)
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), testToken)
+	var apiRecords []apiRecord
+	err := json.Unmarshal(recorder.Body.Bytes(), &apiRecords)
+	require.NoError(t, err)
+	require.Len(t, apiRecords, 2)
+	assert.Equal(t, db.records[0].ID, apiRecords[0].ID)
+	assert.Equal(t, "a.duckdns.org", apiRecords[0].Settings.FQDN)
+	assert.Equal(t, db.records[1].ID, apiRecords[1].ID)
+	assert.Equal(t, "b.duckdns.org", apiRecords[1].Settings.FQDN)
+}
+
+func Test_handlers_apiRecord(t *testing.T) {
+	t.Parallel()
+
+	record := newTestRecord(t, "a")
+
+	testCases := map[string]struct {
+		path   string
+		status int
+	}{
+		"record": {
+			path:   "/api/v1/records/" + record.ID,
+			status: http.StatusOK,
+		},
+		"record history": {
+			path:   "/api/v1/records/" + record.ID + "/history",
+			status: http.StatusOK,
+		},
+		"unknown record": {
+			path:   "/api/v1/records/unknown",
+			status: http.StatusNotFound,
+		},
+		"unknown record history": {
+			path:   "/api/v1/records/unknown/history",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := &testDatabase{records: []records.Record{record}}
+			handler := newHandler(context.Background(), "", db, &testRunner{}, nil, nil, false)
+			request := httptest.NewRequest(http.MethodGet, testCase.path, nil)
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, testCase.status, recorder.Code)
+			body := recorder.Body.String()
+			assert.NotContains(t, body, testToken)
+			if testCase.status == http.StatusOK {
+				assert.Contains(t, body, `"id": "`+record.ID+`"`)
+			}
+		})
+	}
+}
+
+func Test_handlers_apiUpdateRecord(t *testing.T) {
+	t.Parallel()
+
+	record := newTestRecord(t, "a")
+	errTest := errors.New("test error")
+
+	testCases := map[string]struct {
+		id         string
+		query      string
+		recordErrs []error
+		status     int
+		errMessage string
+	}{
+		"success": {
+			id:     record.ID,
+			status: http.StatusOK,
+		},
+		"unconditional": {
+			id:     record.ID,
+			query:  "?unconditional=true",
+			status: http.StatusOK,
+		},
+		"malformed unconditional": {
+			id:         record.ID,
+			query:      "?unconditional=x",
+			status:     http.StatusBadRequest,
+			errMessage: `parsing unconditional query parameter: strconv.ParseBool: parsing "x": invalid syntax`,
+		},
+		"unknown record": {
+			id:         "unknown",
+			recordErrs: []error{fmt.Errorf("%w: for id unknown", data.ErrRecordNotFound)},
+			status:     http.StatusNotFound,
+		},
+		"disabled record": {
+			id:         record.ID,
+			recordErrs: []error{update.ErrRecordDisabled},
+			status:     http.StatusConflict,
+		},
+		"update error": {
+			id:         record.ID,
+			recordErrs: []error{errTest},
+			status:     http.StatusInternalServerError,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := &testDatabase{records: []records.Record{record}}
+			runner := &testRunner{recordErrs: testCase.recordErrs}
+			handler := newHandler(context.Background(), "", db, runner, nil, nil, false)
+			request := httptest.NewRequest(http.MethodPost,
+				"/api/v1/records/"+testCase.id+"/update"+testCase.query, nil)
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, testCase.status, recorder.Code)
+			body := recorder.Body.String()
+			assert.NotContains(t, body, testToken)
+			switch {
+			case testCase.status == http.StatusOK:
+				assert.Contains(t, body, `"id": "`+record.ID+`"`)
+			case testCase.errMessage != "":
+				var errBody errJSONWrapper
+				err := json.Unmarshal(recorder.Body.Bytes(), &errBody)
+				require.NoError(t, err)
+				assert.Equal(t, testCase.errMessage, errBody.Error)
+			default:
+				var errsBody errorsJSONWrapper
+				err := json.Unmarshal(recorder.Body.Bytes(), &errsBody)
+				require.NoError(t, err)
+				require.Len(t, errsBody.Errors, 1)
+				assert.Equal(t, testCase.recordErrs[0].Error(), errsBody.Errors[0])
+			}
+		})
+	}
+}
+
+func Test_handlers_update(t *testing.T) {
+	t.Parallel()
+
+	const rootURL = "/ddns"
+	db := &testDatabase{}
+	handler := newHandler(context.Background(), rootURL, db, &testRunner{}, nil, nil, false)
+	request := httptest.NewRequest(http.MethodPost, rootURL+"/update", nil)
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	var job jobs.Job
+	err := json.Unmarshal(recorder.Body.Bytes(), &job)
+	require.NoError(t, err)
+	assert.NotEmpty(t, job.ID)
+	assert.Equal(t, jobs.StatusRunning, job.Status)
+	assert.False(t, job.Started.IsZero())
+	location := recorder.Header().Get("Location")
+	assert.Equal(t, rootURL+"/api/v1/jobs/"+job.ID, location)
+
+	request = httptest.NewRequest(http.MethodGet, location, nil)
+	recorder = httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"id": "`+job.ID+`"`)
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+)
+
+// defaultAuditLimit is the maximum number of audit log
+// entries returned if no limit is given in the query, and
+// maxAuditLimit is the maximum number of entries returned.
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 10000
+)
+
+func (h *handlers) audit(w http.ResponseWriter, r *http.Request) {
+	if h.auditQuerier == nil {
+		httpError(w, http.StatusNotFound, "audit log is disabled")
+		return
+	}
+
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entries, err := h.auditQuerier.Query(filter)
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if entries == nil {
+		entries = []models.AuditEntry{} // encode as [] instead of null
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	err = encoder.Encode(entries)
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+var ErrLimitNegative = errors.New("limit cannot be negative")
+
+// parseAuditFilter parses the audit filter from the query parameters
+// record, from, to (RFC3339 times) and limit (0 for the maximum limit).
+func parseAuditFilter(values url.Values) (filter models.AuditFilter, err error) {
+	filter.RecordID = values.Get("record")
+
+	filter.From, filter.To, err = parseTimeRange(values)
+	if err != nil {
+		return filter, err
+	}
+
+	filter.Limit = defaultAuditLimit
+	if s := values.Get("limit"); s != "" {
+		filter.Limit, err = strconv.Atoi(s)
+		if err != nil {
+			return filter, fmt.Errorf("parsing limit: %w", err)
+		} else if filter.Limit < 0 {
+			return filter, fmt.Errorf("%w: %d", ErrLimitNegative, filter.Limit)
+		}
+	}
+	if filter.Limit == 0 || filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+
+	return filter, nil
+}
+
+// parseTimeRange parses the optional from and to RFC3339 times from
+// the query parameters, leaving them as the zero time if not set.
+func parseTimeRange(values url.Values) (from, to time.Time, err error) {
+	if s := values.Get("from"); s != "" {
+		from, err = time.Parse(time.RFC3339, s)
+		if err != nil {
+			return from, to, fmt.Errorf("parsing from time: %w", err)
+		}
+	}
+
+	if s := values.Get("to"); s != "" {
+		to, err = time.Parse(time.RFC3339, s)
+		if err != nil {
+			return from, to, fmt.Errorf("parsing to time: %w", err)
+		}
+	}
+
+	return from, to, nil
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/qdm12/ddns-updater/internal/auth"
+)
+
+// authorize returns a middleware responding with an error to requests
+// not authenticated, or authenticated without the scope required.
+// All requests are authorized if authentication is disabled.
+func (h *handlers) authorize(required auth.Scope) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if h.authenticator == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, scope, err := h.authenticator.Authenticate(r)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", h.authenticator.Challenge())
+				httpError(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+
+			if !scope.Allows(required) {
+				httpError(w, http.StatusForbidden, "user "+user+" has scope "+
+					string(scope)+" but scope "+string(required)+" is required")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -9,41 +9,88 @@ import (
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
+	"github.com/qdm12/ddns-updater/internal/auth"
+	"github.com/qdm12/ddns-updater/internal/jobs"
 )
 
 type handlers struct {
-	ctx context.Context //nolint:containedctx
+	ctx     context.Context //nolint:containedctx
+	rootURL string
 	// Objects
 	db            Database
-	runner        UpdateForcer
-	indexTemplate *template.Template
+	runner        Runner
+	jobs          JobManager
+	auditQuerier  AuditQuerier
+	authenticator Authenticator
+	// requireClientCert is true if the routes except
+	// the web UI page require a client certificate.
+	requireClientCert bool
+	indexTemplate     *template.Template
 	// Mockable functions
 	timeNow func() time.Time
 }
 
+// maxJobs is the maximum number of recent update jobs kept in memory.
+const maxJobs = 50
+
 //go:embed ui/*
 var uiFS embed.FS
 
-func newHandler(ctx context.Context, rootURL string,
-	db Database, runner UpdateForcer) http.Handler {
+func newHandler(ctx context.Context, rootURL string, db Database, runner Runner,
+	auditQuerier AuditQuerier, authenticator Authenticator, requireClientCert bool) http.Handler {
 	indexTemplate := template.Must(template.ParseFS(uiFS, "ui/index.html"))
 
 	handlers := &handlers{
 		ctx:           ctx,
+		rootURL:       rootURL,
 		db:            db,
 		indexTemplate: indexTemplate,
 		// TODO build information
-		timeNow: time.Now,
-		runner:  runner,
+		timeNow:           time.Now,
+		runner:            runner,
+		jobs:              jobs.NewManager(runner, maxJobs, time.Now),
+		auditQuerier:      auditQuerier,
+		authenticator:     authenticator,
+		requireClientCert: requireClientCert,
 	}
 
 	router := chi.NewRouter()
 
 	router.Use(middleware.Logger)
 
-	router.Get(rootURL+"/", handlers.index)
+	read := handlers.authorize(auth.ScopeRead)
+	write := handlers.authorize(auth.ScopeWrite)
+
+	router.With(read).Get(rootURL+"/", handlers.index)
+
+	// All the routes except the web UI page require a client
+	// certificate if client certificates are verified.
+	router.Group(func(r chi.Router) {
+		if requireClientCert {
+			r.Use(requireClientCertificate)
+		}
+
+		r.With(write).Post(rootURL+"/update", handlers.update)
+
+		r.With(read).Get(rootURL+"/plan", handlers.plan)
+
+		r.With(read).Get(rootURL+"/audit", handlers.audit)
+
+		r.With(read).Get(rootURL+"/history", handlers.exportHistory)
+
+		r.With(write).Post(rootURL+"/history", handlers.importHistory)
 
-	router.Get(rootURL+"/update", handlers.update)
+		r.Route(rootURL+"/api/v1", func(r chi.Router) {
+			r.With(read).Get("/records", handlers.apiRecords)
+			r.With(read).Get("/records/{id}", handlers.apiRecord)
+			r.With(read).Get("/records/{id}/history", handlers.apiRecordHistory)
+			r.With(write).Post("/records/{id}/update", handlers.apiUpdateRecord)
+			r.With(read).Get("/ips", handlers.apiPublicIPs)
+			r.With(read).Get("/runner", handlers.apiRunnerState)
+			r.With(read).Get("/jobs", handlers.apiJobs)
+			r.With(read).Get("/jobs/{id}", handlers.apiJob)
+		})
+	})
 
 	return router
 }
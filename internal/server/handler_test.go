@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/data"
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/qdm12/ddns-updater/internal/records"
+	"github.com/qdm12/ddns-updater/internal/settings"
+	"github.com/qdm12/ddns-updater/internal/settings/constants"
+	"github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
+	"github.com/stretchr/testify/require"
+)
+
+// testToken is the secret DuckDNS token of the test records.
+const testToken = "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"
+
+// newTestRecord returns a DuckDNS record for the host given.
+func newTestRecord(t *testing.T, host string) records.Record {
+	t.Helper()
+	raw := json.RawMessage(`{"token":"` + testToken + `"}`)
+	s, err := settings.New(constants.DuckDNS, raw, "", host, ipversion.IP4)
+	require.NoError(t, err)
+	return records.Record{ID: settings.ID(s), Settings: s}
+}
+
+type testDatabase struct {
+	Database
+	records []records.Record
+}
+
+func (db *testDatabase) Select(id string) (record records.Record, err error) {
+	for _, record := range db.records {
+		if record.ID == id {
+			return record, nil
+		}
+	}
+	return record, fmt.Errorf("%w: for id %s", data.ErrRecordNotFound, id)
+}
+
+func (db *testDatabase) SelectAll() (records []records.Record) {
+	return db.records
+}
+
+func (db *testDatabase) SelectEvents(models.HistoryFilter) (events []models.RecordEvent) {
+	return nil
+}
+
+func (db *testDatabase) ImportEvents([]models.RecordEvent, time.Time) (
+	result models.ImportResult, err error) {
+	return result, nil
+}
+
+type testRunner struct {
+	Runner
+	// recordErrs are the errors returned by ForceUpdateRecord.
+	recordErrs []error
+}
+
+func (r *testRunner) ForceUpdate(context.Context) (
+	results []models.AuditEntry, errs []error) {
+	return nil, nil
+}
+
+func (r *testRunner) ForceUpdateRecord(context.Context, string, bool) (errs []error) {
+	return r.recordErrs
+}
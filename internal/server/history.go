@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/history"
+	"github.com/qdm12/ddns-updater/internal/models"
+)
+
+func (h *handlers) exportHistory(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	format, err := parseHistoryFormat(values, "")
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := models.HistoryFilter{RecordID: values.Get("record")}
+	filter.From, filter.To, err = parseTimeRange(values)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	events := h.db.SelectEvents(filter)
+
+	w.Header().Set("Content-Type", format.ContentType())
+	if format == history.CSV {
+		w.Header().Set("Content-Disposition", `attachment; filename="history.csv"`)
+	}
+	err = history.Encode(w, format, events)
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+const (
+	// maxImportSize is the maximum size of the request body to import history.
+	maxImportSize = 32 << 20
+	// importReadTimeout is the maximum duration to read the request body
+	// to import history, replacing the short server read timeout.
+	importReadTimeout = time.Minute
+)
+
+func (h *handlers) importHistory(w http.ResponseWriter, r *http.Request) {
+	err := http.NewResponseController(w).SetReadDeadline(h.timeNow().Add(importReadTimeout))
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, "setting read deadline: "+err.Error())
+		return
+	}
+
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	format, err := parseHistoryFormat(r.URL.Query(), contentType)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+	events, err := history.Decode(body, format)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.db.ImportEvents(events, h.timeNow())
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// parseHistoryFormat parses the history format from the format query
+// parameter, defaulting to CSV if the content type given is text/csv,
+// and to JSON otherwise.
+func parseHistoryFormat(values url.Values, contentType string) (
+	format history.Format, err error) {
+	s := values.Get("format")
+	switch {
+	case s != "":
+		return history.ParseFormat(s)
+	case contentType == history.CSV.ContentType():
+		return history.CSV, nil
+	default:
+		return history.JSON, nil
+	}
+}
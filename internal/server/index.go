@@ -6,8 +6,11 @@ import (
 	"github.com/qdm12/ddns-updater/internal/models"
 )
 
-func (h *handlers) index(w http.ResponseWriter, _ *http.Request) {
-	var htmlData models.HTMLData
+func (h *handlers) index(w http.ResponseWriter, r *http.Request) {
+	// the update buttons call routes requiring a client certificate
+	htmlData := models.HTMLData{
+		Actions: !h.requireClientCert || hasVerifiedClientCertificate(r),
+	}
 	for _, record := range h.db.SelectAll() {
 		row := record.HTML(h.timeNow())
 		htmlData.Rows = append(htmlData.Rows, row)
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qdm12/ddns-updater/internal/records"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_handlers_index(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		requireClientCert bool
+		tlsState          *tls.ConnectionState
+		actions           bool
+	}{
+		"client certificates not required": {
+			actions: true,
+		},
+		"no client certificate": {
+			requireClientCert: true,
+			tlsState:          &tls.ConnectionState{},
+		},
+		"verified client certificate": {
+			requireClientCert: true,
+			tlsState: &tls.ConnectionState{
+				VerifiedChains: [][]*x509.Certificate{{{}}},
+			},
+			actions: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := &testDatabase{records: []records.Record{newTestRecord(t, "a")}}
+			handler := newHandler(context.Background(), "", db, nil, nil, nil,
+				testCase.requireClientCert)
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.TLS = testCase.tlsState
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			body := recorder.Body.String()
+			assert.Contains(t, body, "a.duckdns.org")
+			assert.Equal(t, testCase.actions, strings.Contains(body, "updateRecord('"))
+		})
+	}
+}
@@ -2,16 +2,56 @@ package server
 
 import (
 	"context"
+	"net/http"
+	"time"
 
+	"github.com/qdm12/ddns-updater/internal/auth"
+	"github.com/qdm12/ddns-updater/internal/jobs"
+	"github.com/qdm12/ddns-updater/internal/models"
 	"github.com/qdm12/ddns-updater/internal/records"
+	"github.com/qdm12/ddns-updater/internal/update"
 )
 
 type Database interface {
+	Select(id string) (record records.Record, err error)
 	SelectAll() (records []records.Record)
+	SelectEvents(filter models.HistoryFilter) (events []models.RecordEvent)
+	ImportEvents(events []models.RecordEvent, now time.Time) (
+		result models.ImportResult, err error)
 }
 
 type UpdateForcer interface {
-	ForceUpdate(ctx context.Context) (errors []error)
+	ForceUpdate(ctx context.Context) (results []models.AuditEntry, errors []error)
+	ForceUpdateRecord(ctx context.Context, id string, unconditional bool) (errors []error)
+}
+
+type Planner interface {
+	Plan(ctx context.Context) (plan update.Plan)
+}
+
+type StateGetter interface {
+	State() (state update.State)
+}
+
+type Runner interface {
+	UpdateForcer
+	Planner
+	StateGetter
+}
+
+type JobManager interface {
+	Start(ctx context.Context) (job jobs.Job, started bool, err error)
+	Get(id string) (job jobs.Job, err error)
+	List() (jobs []jobs.Job)
+}
+
+type AuditQuerier interface {
+	Query(filter models.AuditFilter) (entries []models.AuditEntry, err error)
+}
+
+type Authenticator interface {
+	Authenticate(request *http.Request) (user string, scope auth.Scope, err error)
+	Challenge() (challenge string)
 }
 
 type Logger interface {
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/qdm12/ddns-updater/internal/jobs"
+)
+
+func (h *handlers) apiJobs(w http.ResponseWriter, _ *http.Request) {
+	encodeJSON(w, h.jobs.List())
+}
+
+func (h *handlers) apiJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.jobs.Get(chi.URLParam(r, "id"))
+	switch {
+	case errors.Is(err, jobs.ErrJobNotFound):
+		httpError(w, http.StatusNotFound, err.Error())
+		return
+	case err != nil:
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	encodeJSON(w, job)
+}
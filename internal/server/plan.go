@@ -0,0 +1,18 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+func (h *handlers) plan(w http.ResponseWriter, r *http.Request) {
+	plan := h.runner.Plan(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(plan)
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
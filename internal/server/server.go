@@ -2,23 +2,34 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"net/http"
 	"time"
 )
 
 type Server struct {
-	address string
-	logger  Logger
-	handler http.Handler
+	address   string
+	logger    Logger
+	handler   http.Handler
+	tlsConfig *tls.Config
 }
 
-func New(ctx context.Context, address, rootURL string, db Database,
-	logger Logger, runner UpdateForcer) *Server {
-	handler := newHandler(ctx, rootURL, db, runner)
+// New creates a new server. The audit querier can be nil if the audit
+// log is disabled, the authenticator can be nil to disable authentication
+// and the TLS configuration can be nil to serve plain HTTP. If the TLS
+// configuration has client certificate authorities, all the routes
+// except the web UI page require a verified client certificate.
+func New(ctx context.Context, address, rootURL string, db Database, logger Logger,
+	runner Runner, auditQuerier AuditQuerier, authenticator Authenticator,
+	tlsConfig *tls.Config) *Server {
+	requireClientCert := tlsConfig != nil && tlsConfig.ClientCAs != nil
+	handler := newHandler(ctx, rootURL, db, runner, auditQuerier, authenticator,
+		requireClientCert)
 	return &Server{
-		address: address,
-		logger:  logger,
-		handler: handler,
+		address:   address,
+		logger:    logger,
+		handler:   handler,
+		tlsConfig: tlsConfig,
 	}
 }
 
@@ -29,6 +40,7 @@ func (s *Server) Run(ctx context.Context, done chan<- struct{}) {
 		Handler:           s.handler,
 		ReadHeaderTimeout: time.Second,
 		ReadTimeout:       time.Second,
+		TLSConfig:         s.tlsConfig,
 	}
 	go func() {
 		<-ctx.Done()
@@ -43,8 +55,14 @@ func (s *Server) Run(ctx context.Context, done chan<- struct{}) {
 		}
 	}()
 	for ctx.Err() == nil {
-		s.logger.Info("listening on " + s.address)
-		err := server.ListenAndServe()
+		var err error
+		if s.tlsConfig != nil {
+			s.logger.Info("listening on " + s.address + " with TLS")
+			err = server.ListenAndServeTLS("", "") // certificate from TLS config
+		} else {
+			s.logger.Info("listening on " + s.address)
+			err = server.ListenAndServe()
+		}
 		if err != nil && ctx.Err() == nil { // server crashed
 			s.logger.Error(err.Error())
 			s.logger.Info("restarting")
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrClientCAInvalid = errors.New("no valid PEM certificate found in client CA file")
+
+// NewTLSConfig returns a TLS configuration serving the certificate and key
+// files given, which are reloaded when they change. If clientCAFile is not
+// empty, client certificates presented are verified against the certificate
+// authorities it contains, and all the routes except the web UI page
+// require a verified client certificate.
+func NewTLSConfig(certFile, keyFile, clientCAFile string, logger Logger) (
+	config *tls.Config, err error) {
+	reloader := &certificateReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		logger:   logger,
+		timeNow:  time.Now,
+	}
+	err = reloader.reloadIfChanged()
+	if err != nil {
+		return nil, err
+	}
+
+	config = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.getCertificate,
+	}
+
+	if clientCAFile != "" {
+		pemData, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading client CA file: %w", err)
+		}
+		config.ClientCAs = x509.NewCertPool()
+		if !config.ClientCAs.AppendCertsFromPEM(pemData) {
+			return nil, fmt.Errorf("%w: %s", ErrClientCAInvalid, clientCAFile)
+		}
+		// Client certificates are not required for the web UI
+		// page, so it stays reachable from a browser without one.
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return config, nil
+}
+
+// certificateCheckPeriod is the minimum period between two
+// checks of the certificate and key files for changes.
+const certificateCheckPeriod = 5 * time.Second
+
+// certificateReloader serves the certificate from the certificate and key
+// files, and reloads them when their modification time changes.
+type certificateReloader struct {
+	certFile    string
+	keyFile     string
+	certificate *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+	lastCheck   time.Time
+	mutex       sync.Mutex
+	logger      Logger
+	timeNow     func() time.Time
+}
+
+func (c *certificateReloader) getCertificate(*tls.ClientHelloInfo) (
+	certificate *tls.Certificate, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.timeNow()
+	if now.Sub(c.lastCheck) >= certificateCheckPeriod {
+		c.lastCheck = now
+		err = c.reloadIfChanged()
+		if err != nil {
+			c.logger.Error("reloading TLS certificate, keeping current certificate: " + err.Error())
+		}
+	}
+	return c.certificate, nil
+}
+
+// reloadIfChanged loads the certificate and key files if their modification
+// time changed since they were last loaded. On error, the files are loaded
+// again at the next check, for example if the certificate file was written
+// but not the key file yet.
+func (c *certificateReloader) reloadIfChanged() (err error) {
+	certStat, err := os.Stat(c.certFile)
+	if err != nil {
+		return err
+	}
+	keyStat, err := os.Stat(c.keyFile)
+	if err != nil {
+		return err
+	}
+	if certStat.ModTime().Equal(c.certModTime) && keyStat.ModTime().Equal(c.keyModTime) {
+		return nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+
+	if c.certificate != nil {
+		c.logger.Info("reloaded TLS certificate from " + c.certFile)
+	}
+	c.certificate = &certificate
+	c.certModTime = certStat.ModTime()
+	c.keyModTime = keyStat.ModTime()
+	return nil
+}
+
+func hasVerifiedClientCertificate(r *http.Request) bool {
+	return r.TLS != nil && len(r.TLS.VerifiedChains) > 0
+}
+
+// requireClientCertificate is a middleware responding with an error
+// to requests without a client certificate verified by the server.
+func requireClientCertificate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !hasVerifiedClientCertificate(r) {
+			httpError(w, http.StatusForbidden, "a valid client certificate is required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
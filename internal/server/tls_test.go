@@ -0,0 +1,169 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLogger struct {
+	mutex  sync.Mutex
+	infos  []string
+	errors []string
+}
+
+func (l *testLogger) Info(s string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.infos = append(l.infos, s)
+}
+
+func (l *testLogger) Warn(string) {}
+
+func (l *testLogger) Error(s string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.errors = append(l.errors, s)
+}
+
+// writeTestCertificate writes a self-signed certificate for the common
+// name given and its key to the files given, setting their modification
+// time to modTime.
+func writeTestCertificate(t *testing.T, certFile, keyFile, commonName string,
+	modTime time.Time) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	for path, data := range map[string][]byte{certFile: certPEM, keyFile: keyPEM} {
+		err = os.WriteFile(path, data, 0o600)
+		require.NoError(t, err)
+		err = os.Chtimes(path, modTime, modTime)
+		require.NoError(t, err)
+	}
+}
+
+func commonNameOf(t *testing.T, certificate *tls.Certificate) string {
+	t.Helper()
+	parsed, err := x509.ParseCertificate(certificate.Certificate[0])
+	require.NoError(t, err)
+	return parsed.Subject.CommonName
+}
+
+func Test_certificateReloader(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	modTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	writeTestCertificate(t, certFile, keyFile, "first", modTime)
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	logger := &testLogger{}
+	reloader := &certificateReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		logger:   logger,
+		timeNow:  func() time.Time { return now },
+	}
+	err := reloader.reloadIfChanged()
+	require.NoError(t, err)
+	reloader.lastCheck = now
+
+	certificate, err := reloader.getCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "first", commonNameOf(t, certificate))
+
+	// files changed but not checked again yet
+	writeTestCertificate(t, certFile, keyFile, "second", modTime.Add(time.Minute))
+	now = now.Add(certificateCheckPeriod / 2)
+	certificate, err = reloader.getCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "first", commonNameOf(t, certificate))
+
+	now = now.Add(certificateCheckPeriod)
+	certificate, err = reloader.getCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "second", commonNameOf(t, certificate))
+	assert.Equal(t, []string{"reloaded TLS certificate from " + certFile}, logger.infos)
+
+	// invalid new certificate file
+	err = os.WriteFile(certFile, []byte("invalid"), 0o600)
+	require.NoError(t, err)
+	err = os.Chtimes(certFile, modTime.Add(2*time.Minute), modTime.Add(2*time.Minute))
+	require.NoError(t, err)
+	now = now.Add(certificateCheckPeriod)
+	certificate, err = reloader.getCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "second", commonNameOf(t, certificate))
+	assert.Len(t, logger.errors, 1)
+}
+
+func Test_requireClientCertificate(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		tlsState *tls.ConnectionState
+		status   int
+	}{
+		"plain HTTP": {
+			status: http.StatusForbidden,
+		},
+		"no client certificate": {
+			tlsState: &tls.ConnectionState{},
+			status:   http.StatusForbidden,
+		},
+		"verified client certificate": {
+			tlsState: &tls.ConnectionState{
+				VerifiedChains: [][]*x509.Certificate{{{}}},
+			},
+			status: http.StatusOK,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := requireClientCertificate(http.HandlerFunc(
+				func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }))
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.TLS = testCase.tlsState
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, testCase.status, recorder.Code)
+		})
+	}
+}
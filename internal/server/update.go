@@ -1,18 +1,65 @@
 package server
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/qdm12/ddns-updater/internal/data"
+	"github.com/qdm12/ddns-updater/internal/update"
 )
 
+// update starts a job updating all the records in the background, and
+// responds with the job, which can be polled at the Location header URL.
+// If an update job is already running, it responds with this job instead.
 func (h *handlers) update(w http.ResponseWriter, _ *http.Request) {
-	start := h.timeNow()
-	errors := h.runner.ForceUpdate(h.ctx)
-	duration := h.timeNow().Sub(start)
-	if len(errors) > 0 {
-		httpErrors(w, http.StatusInternalServerError, errors)
+	job, _, err := h.jobs.Start(h.ctx)
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	w.Header().Set("Location", h.rootURL+"/api/v1/jobs/"+job.ID)
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
-	message := "All records updated successfully in " + duration.String()
-	_, _ = w.Write([]byte(message))
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(job)
+}
+
+// apiUpdateRecord updates a single record right away and responds with
+// the record updated. If the query parameter unconditional is true, the
+// record is updated regardless of its cooldown and DNS resolution.
+func (h *handlers) apiUpdateRecord(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	var unconditional bool
+	if s := r.URL.Query().Get("unconditional"); s != "" {
+		var err error
+		unconditional, err = strconv.ParseBool(s)
+		if err != nil {
+			httpError(w, http.StatusBadRequest, "parsing unconditional query parameter: "+err.Error())
+			return
+		}
+	}
+
+	errs := h.runner.ForceUpdateRecord(h.ctx, id, unconditional)
+	if len(errs) > 0 {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(errs[0], data.ErrRecordNotFound):
+			status = http.StatusNotFound
+		case errors.Is(errs[0], update.ErrRecordDisabled):
+			status = http.StatusConflict
+		}
+		httpErrors(w, status, errs)
+		return
+	}
+
+	record, ok := h.selectRecord(w, r)
+	if !ok {
+		return
+	}
+	encodeJSON(w, makeAPIRecord(record))
 }
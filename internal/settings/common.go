@@ -0,0 +1,44 @@
+package settings
+
+import "time"
+
+// Common contains optional settings common to all providers,
+// overriding the program wide defaults for a single record.
+type Common struct {
+	// ID is the explicit identifier of the record,
+	// and is empty to derive it from the settings.
+	ID string
+	// Period is the period to check the record.
+	// It defaults to the program wide period if zero.
+	Period time.Duration
+	// Cooldown is the minimum duration between two successful
+	// updates of the record. It defaults to the program wide
+	// cooldown if nil.
+	Cooldown *time.Duration
+	// Raw is the compacted JSON object the settings are parsed from,
+	// used to detect changes to provider specific settings.
+	Raw string
+}
+
+type withCommon struct {
+	Settings
+	common Common
+}
+
+// WithCommon returns the provider settings with the common settings attached.
+func WithCommon(settings Settings, common Common) Settings { //nolint:ireturn
+	return &withCommon{
+		Settings: settings,
+		common:   common,
+	}
+}
+
+// GetCommon returns the common settings attached to the
+// settings given, or empty common settings if there is none.
+func GetCommon(settings Settings) (common Common) {
+	s, ok := settings.(*withCommon)
+	if !ok {
+		return common
+	}
+	return s.common
+}
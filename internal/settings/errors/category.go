@@ -0,0 +1,98 @@
+package errors
+
+import "errors"
+
+// Category is the category of an update error, used
+// to decide how to handle a failing record.
+type Category string
+
+const (
+	// CategoryTransient is for temporary errors such as network
+	// errors, which should be retried.
+	CategoryTransient Category = "transient"
+	// CategoryRateLimited is for errors due to the provider banning
+	// or rate limiting the client, which should be retried later.
+	CategoryRateLimited Category = "rate limited"
+	// CategoryAuth is for authentication and account errors,
+	// which cannot be resolved by retrying.
+	CategoryAuth Category = "authentication"
+	// CategoryConfig is for errors due to the record configuration,
+	// which cannot be resolved by retrying.
+	CategoryConfig Category = "configuration"
+	// CategoryProvider is for errors on the provider side such as
+	// unexpected responses, which should be retried.
+	CategoryProvider Category = "provider"
+)
+
+// IsPermanent returns true if retrying the update cannot succeed
+// without an action from the user.
+func (c Category) IsPermanent() bool {
+	return c == CategoryAuth || c == CategoryConfig
+}
+
+//nolint:gochecknoglobals
+var (
+	authErrors = []error{
+		ErrAuth,
+		ErrAccountInactive,
+		ErrFeatureUnavailable,
+	}
+	configErrors = []error{
+		ErrBannedUserAgent,
+		ErrConflictingRecord,
+		ErrDomainDisabled,
+		ErrDomainIDNotFound,
+		ErrHostnameNotExists,
+		ErrInvalidSystemParam,
+		ErrIPv6NotSupported,
+		ErrRecordNotEditable,
+		ErrRecordNotFound,
+		ErrZoneNotFound,
+	}
+	rateLimitedErrors = []error{
+		ErrAbuse,
+	}
+	providerErrors = []error{
+		ErrBadHTTPStatus,
+		ErrBadRequest,
+		ErrDNSServerSide,
+		ErrIPReceivedMalformed,
+		ErrIPReceivedMismatch,
+		// the IP address sent may be wrong temporarily
+		ErrMalformedIPSent,
+		ErrNoIPInResponse,
+		ErrNoResultReceived,
+		ErrNotFound,
+		ErrNumberOfResultsReceived,
+		ErrPrivateIPSent,
+		ErrUnknownResponse,
+		ErrUnmarshalResponse,
+		ErrUnsuccessfulResponse,
+	}
+)
+
+// Categorize returns the category of the given update error.
+// Errors not matching any known sentinel error are transient.
+func Categorize(err error) Category {
+	switch {
+	case isAny(err, authErrors):
+		return CategoryAuth
+	case isAny(err, configErrors):
+		return CategoryConfig
+	case isAny(err, rateLimitedErrors):
+		return CategoryRateLimited
+	case isAny(err, providerErrors):
+		return CategoryProvider
+	default:
+		return CategoryTransient
+	}
+}
+
+func isAny(err error, targets []error) bool {
+	for _, target := range targets {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Categorize(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		err      error
+		category Category
+	}{
+		"unknown error": {
+			err:      errors.New("dial tcp: connection refused"),
+			category: CategoryTransient,
+		},
+		"context deadline": {
+			err:      context.DeadlineExceeded,
+			category: CategoryTransient,
+		},
+		"authentication": {
+			err:      fmt.Errorf("%w", ErrAuth),
+			category: CategoryAuth,
+		},
+		"wrapped configuration": {
+			err:      fmt.Errorf("%w: %w", ErrGetRecordID, ErrZoneNotFound),
+			category: CategoryConfig,
+		},
+		"abuse": {
+			err:      ErrAbuse,
+			category: CategoryRateLimited,
+		},
+		"malformed IP sent": {
+			err:      ErrMalformedIPSent,
+			category: CategoryProvider,
+		},
+		"private IP sent": {
+			err:      ErrPrivateIPSent,
+			category: CategoryProvider,
+		},
+		"bad HTTP status": {
+			err:      fmt.Errorf("%w: %d", ErrBadHTTPStatus, 500),
+			category: CategoryProvider,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			category := Categorize(testCase.err)
+
+			assert.Equal(t, testCase.category, category)
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package settings
+
+import (
+	"fmt"
+)
+
+// Fingerprint returns a string which changes if any of the settings
+// given change, including provider specific settings such as
+// credentials, so it can be compared to detect settings changes.
+func Fingerprint(s Settings) string {
+	common := GetCommon(s)
+	cooldown := "default"
+	if common.Cooldown != nil {
+		cooldown = common.Cooldown.String()
+	}
+	return fmt.Sprintf("%s|%s|%s|%s|%s|%s|%s|%s",
+		s.Provider(), s.Domain(), s.Host(), s.IPVersion(),
+		common.ID, common.Period, cooldown, common.Raw)
+}
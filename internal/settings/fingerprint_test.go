@@ -0,0 +1,50 @@
+package settings
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Fingerprint(t *testing.T) {
+	t.Parallel()
+
+	cooldown := time.Minute
+	base := WithCommon(&testSettings{host: "@"}, Common{Raw: `{"token":"a"}`})
+
+	testCases := map[string]struct {
+		settings Settings
+		equal    bool
+	}{
+		"same settings": {
+			settings: WithCommon(&testSettings{host: "@"}, Common{Raw: `{"token":"a"}`}),
+			equal:    true,
+		},
+		"raw settings changed": {
+			settings: WithCommon(&testSettings{host: "@"}, Common{Raw: `{"token":"b"}`}),
+		},
+		"host changed": {
+			settings: WithCommon(&testSettings{host: "a"}, Common{Raw: `{"token":"a"}`}),
+		},
+		"period changed": {
+			settings: WithCommon(&testSettings{host: "@"},
+				Common{Period: time.Hour, Raw: `{"token":"a"}`}),
+		},
+		"cooldown changed": {
+			settings: WithCommon(&testSettings{host: "@"},
+				Common{Cooldown: &cooldown, Raw: `{"token":"a"}`}),
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			equal := Fingerprint(base) == Fingerprint(testCase.settings)
+
+			assert.Equal(t, testCase.equal, equal)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package settings
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+)
+
+// ID returns the identifier of the record settings given. It is the
+// identifier set explicitly in the common settings if any, or otherwise
+// an identifier derived from the provider, domain, host and IP version,
+// so it does not change when the settings are reordered.
+func ID(s Settings) string {
+	if id := GetCommon(s).ID; id != "" {
+		return id
+	}
+	data := string(s.Provider()) + "|" + s.Domain() + "|" + s.Host() + "|" + s.IPVersion().String()
+	digest := sha256.Sum256([]byte(data))
+	const idBytes = 8
+	return hex.EncodeToString(digest[:idBytes])
+}
+
+// Key returns the key of the record settings given
+// to use with the persistent database.
+func Key(s Settings) models.RecordKey {
+	return models.RecordKey{
+		ID:        ID(s),
+		Provider:  s.Provider(),
+		Domain:    s.Domain(),
+		Host:      s.Host(),
+		IPVersion: s.IPVersion(),
+	}
+}
+
+var (
+	ErrIDMalformed = errors.New("id is malformed")
+	idRegex        = regexp.MustCompile(`^[a-zA-Z0-9_.-]{1,64}$`)
+)
+
+// ValidateID returns an error if the explicit record identifier
+// given is not usable as is in URL paths and file names.
+func ValidateID(id string) (err error) {
+	if !idRegex.MatchString(id) {
+		return fmt.Errorf("%w: %q must be 1 to 64 letters, digits, '_', '.' or '-'",
+			ErrIDMalformed, id)
+	}
+	if strings.Trim(id, ".") == "" {
+		// reject "." and ".." which are special in URL paths and file names
+		return fmt.Errorf("%w: %q cannot contain only dots", ErrIDMalformed, id)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSettings struct {
+	Settings
+	host      string
+	ipVersion ipversion.IPVersion
+}
+
+func (s *testSettings) Provider() models.Provider      { return "test" }
+func (s *testSettings) Domain() string                 { return "example.com" }
+func (s *testSettings) Host() string                   { return s.host }
+func (s *testSettings) IPVersion() ipversion.IPVersion { return s.ipVersion }
+
+func Test_ID(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		settings Settings
+		id       string
+	}{
+		"derived": {
+			settings: &testSettings{host: "@", ipVersion: ipversion.IP4},
+			id:       "3599df3d6c8b6e94",
+		},
+		"derived for other IP version": {
+			settings: &testSettings{host: "@", ipVersion: ipversion.IP6},
+			id:       "c8101b9d548c487b",
+		},
+		"derived with common settings": {
+			settings: WithCommon(&testSettings{host: "@", ipVersion: ipversion.IP4}, Common{}),
+			id:       "3599df3d6c8b6e94",
+		},
+		"explicit": {
+			settings: WithCommon(&testSettings{host: "@"}, Common{ID: "home"}),
+			id:       "home",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			id := ID(testCase.settings)
+
+			assert.Equal(t, testCase.id, id)
+		})
+	}
+}
+
+func Test_ValidateID(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		id         string
+		errWrapped error
+		errMessage string
+	}{
+		"valid": {
+			id: "home.v4_a-b",
+		},
+		"empty": {
+			errWrapped: ErrIDMalformed,
+			errMessage: `id is malformed: "" must be 1 to 64 letters, digits, '_', '.' or '-'`,
+		},
+		"invalid character": {
+			id:         "home/a",
+			errWrapped: ErrIDMalformed,
+			errMessage: `id is malformed: "home/a" must be 1 to 64 letters, digits, '_', '.' or '-'`,
+		},
+		"dot": {
+			id:         ".",
+			errWrapped: ErrIDMalformed,
+			errMessage: `id is malformed: "." cannot contain only dots`,
+		},
+		"dot dot": {
+			id:         "..",
+			errWrapped: ErrIDMalformed,
+			errMessage: `id is malformed: ".." cannot contain only dots`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateID(testCase.id)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package settings
+
+import (
+	"fmt"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/qdm12/ddns-updater/internal/settings/constants"
+)
+
+// ProviderKeys returns the JSON keys specific to the provider given,
+// excluding keys common to all providers such as "domain" or "host".
+func ProviderKeys(provider models.Provider) (keys []string, err error) {
+	keys, ok := providerKeys[provider]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProviderUnknown, provider)
+	}
+	return keys, nil
+}
+
+// providerKeys must be kept in sync with the JSON keys parsed
+// in each provider New function.
+//
+//nolint:gochecknoglobals
+var providerKeys = map[models.Provider][]string{
+	constants.Aliyun:       {"access_key_id", "access_secret", "region"},
+	constants.AllInkl:      {"username", "password", "provider_ip"},
+	constants.Cloudflare:   {"key", "token", "email", "user_service_key", "zone_identifier", "proxied", "ttl"},
+	constants.Dd24:         {"password", "provider_ip"},
+	constants.DdnssDe:      {"username", "password", "dual_stack", "provider_ip"},
+	constants.DigitalOcean: {"token"},
+	constants.DNSOMatic:    {"username", "password", "provider_ip"},
+	constants.DNSPod:       {"token"},
+	constants.DonDominio:   {"username", "password", "name"},
+	constants.Dreamhost:    {"key"},
+	constants.DuckDNS:      {"token", "provider_ip"},
+	constants.Dyn:          {"username", "password", "client_key", "provider_ip"},
+	constants.Dynu:         {"username", "password", "provider_ip", "group"},
+	constants.DynV6:        {"token", "provider_ip"},
+	constants.FreeDNS:      {"token"},
+	constants.Gandi:        {"key", "ttl"},
+	constants.GCP:          {"project", "zone", "credentials"},
+	constants.GoDaddy:      {"key", "secret"},
+	constants.Google:       {"username", "password", "provider_ip"},
+	constants.HE:           {"password", "provider_ip"},
+	constants.Infomaniak:   {"username", "password", "provider_ip"},
+	constants.INWX:         {"username", "password"},
+	constants.Linode:       {"token"},
+	constants.LuaDNS:       {"email", "token"},
+	constants.Namecheap:    {"password", "provider_ip"},
+	constants.Njalla:       {"key", "provider_ip"},
+	constants.NoIP:         {"username", "password", "provider_ip"},
+	constants.OpenDNS:      {"username", "password", "provider_ip"},
+	constants.OVH: {"username", "password", "provider_ip", "mode", "api_endpoint",
+		"app_key", "app_secret", "consumer_key"},
+	constants.Porkbun:    {"secret_api_key", "api_key", "ttl"},
+	constants.SelfhostDe: {"username", "password", "provider_ip"},
+	constants.Servercow:  {"username", "password", "domain", "ttl", "provider_ip"},
+	constants.Spdyn:      {"user", "password", "token", "provider_ip"},
+	constants.Strato:     {"password", "provider_ip"},
+	constants.Variomedia: {"email", "password", "provider_ip"},
+}
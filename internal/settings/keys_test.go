@@ -0,0 +1,80 @@
+package settings
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/qdm12/ddns-updater/internal/settings/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_providerKeys(t *testing.T) {
+	t.Parallel()
+
+	for _, provider := range constants.ProviderChoices() {
+		_, ok := providerKeys[provider]
+		assert.True(t, ok, "provider %s has no keys defined", provider)
+	}
+
+	for provider, keys := range providerKeys {
+		provider, keys := provider, keys
+		t.Run(string(provider), func(t *testing.T) {
+			t.Parallel()
+
+			packageName := strings.ReplaceAll(string(provider), ".", "")
+			path := filepath.Join("providers", packageName, "provider.go")
+			parsedKeys := parseNewKeys(t, path)
+			assert.ElementsMatch(t, parsedKeys, keys)
+		})
+	}
+}
+
+// parseNewKeys parses the Go source file at path and returns the JSON
+// tags of the first anonymous struct type in the New function.
+func parseNewKeys(t *testing.T, path string) (keys []string) {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	require.NoError(t, err)
+
+	var newFunction *ast.FuncDecl
+	for _, declaration := range file.Decls {
+		function, ok := declaration.(*ast.FuncDecl)
+		if ok && function.Recv == nil && function.Name.Name == "New" {
+			newFunction = function
+			break
+		}
+	}
+	require.NotNil(t, newFunction, "no New function found in %s", path)
+
+	ast.Inspect(newFunction.Body, func(node ast.Node) bool {
+		if keys != nil {
+			return false
+		}
+		structType, ok := node.(*ast.StructType)
+		if !ok {
+			return true
+		}
+		keys = []string{}
+		for _, field := range structType.Fields.List {
+			if field.Tag == nil {
+				continue
+			}
+			tag, err := strconv.Unquote(field.Tag.Value)
+			require.NoError(t, err)
+			name, _, _ := strings.Cut(reflect.StructTag(tag).Get("json"), ",")
+			keys = append(keys, name)
+		}
+		return false
+	})
+	require.NotNil(t, keys, "no struct type found in New function of %s", path)
+
+	return keys
+}
@@ -70,6 +70,10 @@ func (p *Provider) String() string {
 	return utils.ToString(p.domain, p.host, constants.AllInkl, p.ipVersion)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.AllInkl
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
@@ -100,6 +100,10 @@ func (p *Provider) String() string {
 	return utils.ToString(p.domain, p.host, constants.Cloudflare, p.ipVersion)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.Cloudflare
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
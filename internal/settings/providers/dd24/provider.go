@@ -62,6 +62,10 @@ func (p *Provider) String() string {
 	return utils.ToString(p.domain, p.host, constants.Dd24, p.ipVersion)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.Dd24
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
@@ -72,6 +72,10 @@ func (p *Provider) String() string {
 	return utils.ToString(p.domain, p.host, constants.DdnssDe, p.ipVersion)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.DdnssDe
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
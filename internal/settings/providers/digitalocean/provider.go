@@ -57,6 +57,10 @@ func (p *Provider) String() string {
 	return utils.ToString(p.domain, p.host, constants.DigitalOcean, p.ipVersion)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.DigitalOcean
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
@@ -64,6 +64,10 @@ func (p *Provider) String() string {
 	return utils.ToString(p.domain, p.host, constants.Dreamhost, p.ipVersion)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.Dreamhost
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
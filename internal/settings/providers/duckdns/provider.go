@@ -66,6 +66,10 @@ func (p *Provider) String() string {
 	return utils.ToString("duckdns.org", p.host, constants.DuckDNS, p.ipVersion)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.DuckDNS
+}
+
 func (p *Provider) Domain() string {
 	return "duckdns.org"
 }
@@ -76,6 +76,10 @@ func (p *Provider) String() string {
 	return fmt.Sprintf("[domain: %s | host: %s | provider: Dyn]", p.domain, p.host)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.Dyn
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
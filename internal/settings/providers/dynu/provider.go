@@ -77,6 +77,10 @@ func (p *Provider) String() string {
 	return utils.ToString(p.domain, p.host, constants.Dynu, p.ipVersion)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.Dynu
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
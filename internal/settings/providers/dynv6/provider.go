@@ -9,6 +9,7 @@ import (
 	"net/url"
 
 	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/qdm12/ddns-updater/internal/settings/constants"
 	"github.com/qdm12/ddns-updater/internal/settings/errors"
 	"github.com/qdm12/ddns-updater/internal/settings/headers"
 	"github.com/qdm12/ddns-updater/internal/settings/utils"
@@ -61,6 +62,10 @@ func (p *Provider) String() string {
 	return fmt.Sprintf("[domain: %s | host: %s | provider: DynV6]", p.domain, p.host)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.DynV6
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
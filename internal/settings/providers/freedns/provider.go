@@ -58,6 +58,10 @@ func (p *Provider) String() string {
 	return utils.ToString(p.domain, p.host, constants.FreeDNS, p.ipVersion)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.FreeDNS
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
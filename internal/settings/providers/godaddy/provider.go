@@ -67,6 +67,10 @@ func (p *Provider) String() string {
 	return utils.ToString(p.domain, p.host, constants.GoDaddy, p.ipVersion)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.GoDaddy
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
@@ -68,6 +68,10 @@ func (p *Provider) String() string {
 	return utils.ToString(p.domain, p.host, constants.Google, p.ipVersion)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.Google
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
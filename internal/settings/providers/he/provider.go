@@ -62,6 +62,10 @@ func (p *Provider) String() string {
 	return utils.ToString(p.domain, p.host, constants.HE, p.ipVersion)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.HE
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
@@ -69,6 +69,10 @@ func (p *Provider) String() string {
 	return utils.ToString(p.domain, p.host, constants.Infomaniak, p.ipVersion)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.Infomaniak
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
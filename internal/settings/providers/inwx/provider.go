@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/qdm12/ddns-updater/internal/settings/constants"
 	"github.com/qdm12/ddns-updater/internal/settings/errors"
 	"github.com/qdm12/ddns-updater/internal/settings/headers"
 	"github.com/qdm12/ddns-updater/internal/settings/utils"
@@ -65,6 +66,10 @@ func (p *Provider) String() string {
 	return fmt.Sprintf("[domain: %s | host: %s | provider: INWX]", p.domain, p.host)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.INWX
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
@@ -65,6 +65,10 @@ func (p *Provider) String() string {
 	return utils.ToString(p.domain, p.host, constants.LuaDNS, p.ipVersion)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.LuaDNS
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
@@ -59,6 +59,10 @@ func (p *Provider) String() string {
 	return utils.ToString(p.domain, p.host, constants.Njalla, p.ipVersion)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.Njalla
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
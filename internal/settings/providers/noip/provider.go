@@ -73,6 +73,10 @@ func (p *Provider) String() string {
 	return utils.ToString(p.domain, p.host, constants.NoIP, p.ipVersion)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.NoIP
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
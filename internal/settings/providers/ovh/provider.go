@@ -106,6 +106,10 @@ func (p *Provider) String() string {
 	return fmt.Sprintf("[domain: %s | host: %s | provider: OVH]", p.domain, p.host)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.OVH
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
@@ -66,6 +66,10 @@ func (p *Provider) String() string {
 	return fmt.Sprintf("[domain: %s | host: %s | provider: Porkbun]", p.domain, p.host)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.Porkbun
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
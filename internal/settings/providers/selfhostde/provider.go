@@ -69,6 +69,10 @@ func (p *Provider) String() string {
 	return fmt.Sprintf("[domain: %s | host: %s | provider: Selfhost.de]", p.domain, p.host)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.SelfhostDe
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
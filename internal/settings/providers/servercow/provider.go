@@ -75,6 +75,10 @@ func (p *Provider) String() string {
 	return utils.ToString("servercow.de", p.host, constants.Servercow, p.ipVersion)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.Servercow
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
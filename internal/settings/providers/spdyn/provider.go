@@ -75,6 +75,10 @@ func (p *Provider) String() string {
 	return fmt.Sprintf("[domain: %s | host: %s | provider: Spdyn]", p.domain, p.host)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.Spdyn
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
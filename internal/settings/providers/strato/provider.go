@@ -64,6 +64,10 @@ func (p *Provider) String() string {
 	return fmt.Sprintf("[domain: %s | host: %s | provider: Strato]", p.domain, p.host)
 }
 
+func (p *Provider) Provider() models.Provider {
+	return constants.Strato
+}
+
 func (p *Provider) Domain() string {
 	return p.domain
 }
@@ -50,6 +50,7 @@ import (
 
 type Settings interface {
 	String() string
+	Provider() models.Provider
 	Domain() string
 	Host() string
 	BuildDomainName() string
@@ -0,0 +1,52 @@
+package update
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff computes exponentially increasing retry delays
+// for records failing to update.
+type Backoff struct {
+	initial time.Duration
+	maximum time.Duration
+	// Mockable functions
+	randInt63n func(n int64) int64
+}
+
+// NewBackoff creates a backoff policy doubling the delay from initial
+// on each consecutive failure, up to maximum. An initial duration of
+// 0 disables retries outside of the regular period.
+func NewBackoff(initial, maximum time.Duration) *Backoff {
+	if maximum < initial {
+		maximum = initial
+	}
+	return &Backoff{
+		initial:    initial,
+		maximum:    maximum,
+		randInt63n: rand.Int63n, //nolint:gosec
+	}
+}
+
+// Delay returns the delay to wait before retrying after the given
+// number of consecutive failures. Half of the delay is randomized
+// to spread retries of records failing at the same time.
+func (b *Backoff) Delay(failures uint) (delay time.Duration) {
+	if b.initial == 0 || failures == 0 {
+		return 0
+	}
+
+	delay = b.initial
+	for i := uint(1); i < failures && delay < b.maximum; i++ {
+		delay *= 2
+	}
+	if delay > b.maximum {
+		delay = b.maximum
+	}
+
+	half := delay / 2 //nolint:gomnd
+	if half == 0 {
+		return delay
+	}
+	return half + time.Duration(b.randInt63n(int64(half)))
+}
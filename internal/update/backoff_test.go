@@ -0,0 +1,66 @@
+package update
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Backoff_Delay(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		initial  time.Duration
+		maximum  time.Duration
+		failures uint
+		delay    time.Duration
+	}{
+		"disabled": {
+			maximum:  time.Hour,
+			failures: 3,
+		},
+		"no failure": {
+			initial: time.Minute,
+			maximum: time.Hour,
+		},
+		"first failure": {
+			initial:  time.Minute,
+			maximum:  time.Hour,
+			failures: 1,
+			delay:    time.Minute,
+		},
+		"third failure": {
+			initial:  time.Minute,
+			maximum:  time.Hour,
+			failures: 3,
+			delay:    4 * time.Minute,
+		},
+		"capped to maximum": {
+			initial:  time.Minute,
+			maximum:  time.Hour,
+			failures: 100,
+			delay:    time.Hour,
+		},
+		"maximum below initial": {
+			initial:  time.Minute,
+			maximum:  time.Second,
+			failures: 2,
+			delay:    time.Minute,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			backoff := NewBackoff(testCase.initial, testCase.maximum)
+			backoff.randInt63n = func(n int64) int64 { return n }
+
+			delay := backoff.Delay(testCase.failures)
+
+			assert.Equal(t, testCase.delay, delay)
+		})
+	}
+}
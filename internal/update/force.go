@@ -0,0 +1,96 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/constants"
+	"github.com/qdm12/ddns-updater/internal/models"
+	librecords "github.com/qdm12/ddns-updater/internal/records"
+)
+
+type forceResult struct {
+	results []models.AuditEntry
+	errs    []error
+}
+
+type forceRecordRequest struct {
+	id            string
+	unconditional bool
+	result        chan<- []error
+}
+
+// ForceUpdateRecord checks and updates the record with the identifier
+// given right away. If unconditional is true, the record is updated
+// with the current public IP address regardless of its ban period,
+// cooldown, backoff and of the IP address it resolves to, and a
+// disabled record is enabled again.
+func (r *Runner) ForceUpdateRecord(ctx context.Context, id string,
+	unconditional bool) (errs []error) {
+	result := make(chan []error, 1)
+	request := forceRecordRequest{id: id, unconditional: unconditional, result: result}
+	select {
+	case r.forceRecord <- request:
+	case <-ctx.Done():
+		return []error{ctx.Err()}
+	}
+
+	select {
+	case errs = <-result:
+	case <-ctx.Done():
+		errs = []error{ctx.Err()}
+	}
+	return errs
+}
+
+var ErrRecordDisabled = errors.New("record is disabled")
+
+func (r *Runner) updateRecord(ctx context.Context, request forceRecordRequest) (errs []error) {
+	record, err := r.db.Select(request.id)
+	if err != nil {
+		return []error{err}
+	}
+	switch {
+	case request.unconditional && record.Status == constants.DISABLED && r.dryRun:
+		r.logger.Info("[dry run] would enable disabled record " + record.Settings.BuildDomainName())
+	case request.unconditional && record.Status == constants.DISABLED:
+		// the record may have been fixed manually at the provider
+		r.logger.Info("enabling disabled record " + record.Settings.BuildDomainName())
+		record.Status = constants.UNSET
+		record.ErrorCategory = ""
+		record.Backoff = models.Backoff{}
+		err = r.db.Update(record.ID, record)
+		if err != nil {
+			return []error{err}
+		}
+	case record.Status == constants.DISABLED:
+		return []error{fmt.Errorf("%w: for domain %s", ErrRecordDisabled,
+			record.Settings.BuildDomainName())}
+	}
+
+	selected := func(record librecords.Record, _ time.Time) bool {
+		return record.ID == request.id
+	}
+	_, errs = r.updateNecessary(ctx, r.ipv6Mask, selected, request.unconditional)
+	return errs
+}
+
+// shouldForceUpdateRecord returns true if a public IP address matching
+// the IP version of the record is found, ignoring the record state.
+func shouldForceUpdateRecord(record librecords.Record, ip, ipv4, ipv6 net.IP,
+	logger Logger) (update bool) {
+	domain := record.Settings.BuildDomainName()
+	ipVersion := record.Settings.IPVersion()
+	ip = getIPMatchingVersion(ip, ipv4, ipv6, ipVersion)
+	if ip == nil {
+		logger.Warn("no public " + ipVersion.String() + " address found for " +
+			domain + ", skipping update")
+		return false
+	}
+	logger.Info("Forcing update of " + domain + " to use " + ip.String() +
+		", ignoring its cooldown, ban period, backoff and DNS resolution")
+	return true
+}
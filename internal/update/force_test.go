@@ -0,0 +1,197 @@
+package update
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/constants"
+	"github.com/qdm12/ddns-updater/internal/models"
+	librecords "github.com/qdm12/ddns-updater/internal/records"
+	"github.com/qdm12/ddns-updater/internal/settings"
+	"github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSettings struct {
+	settings.Settings
+	name      string
+	ipVersion ipversion.IPVersion
+	proxied   bool
+	updateErr error
+	// updates counts the calls to Update, and can be nil.
+	updates *atomic.Int32
+}
+
+func (s *testSettings) String() string                 { return s.BuildDomainName() }
+func (s *testSettings) IPVersion() ipversion.IPVersion { return s.ipVersion }
+func (s *testSettings) Proxied() bool                  { return s.proxied }
+func (s *testSettings) BuildDomainName() string {
+	if s.name != "" {
+		return s.name
+	}
+	return "example.com"
+}
+func (s *testSettings) Provider() models.Provider { return "test" }
+func (s *testSettings) Domain() string            { return "example.com" }
+func (s *testSettings) Host() string              { return "@" }
+func (s *testSettings) Update(_ context.Context, _ *http.Client, ip net.IP) (
+	newIP net.IP, err error) {
+	if s.updates != nil {
+		s.updates.Add(1)
+	}
+	if s.updateErr != nil {
+		return nil, s.updateErr
+	}
+	return ip, nil
+}
+
+type testDatabase struct {
+	Database
+	records map[string]librecords.Record
+	updates int
+	mutex   sync.Mutex
+}
+
+func (db *testDatabase) Select(id string) (record librecords.Record, err error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	return db.records[id], nil
+}
+
+func (db *testDatabase) SelectAll() (records []librecords.Record) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	for _, record := range db.records {
+		records = append(records, record)
+	}
+	return records
+}
+
+func (db *testDatabase) Update(id string, record librecords.Record) (err error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	db.updates++
+	db.records[id] = record
+	return nil
+}
+
+type testIPGetter struct {
+	PublicIPFetcher
+	ipv4 net.IP
+}
+
+func (g *testIPGetter) IP4(context.Context) (net.IP, error) { return g.ipv4, nil }
+
+type noopLogger struct{}
+
+func (noopLogger) Debug(string) {}
+func (noopLogger) Info(string)  {}
+func (noopLogger) Warn(string)  {}
+func (noopLogger) Error(string) {}
+
+func Test_shouldForceUpdateRecord(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	ipv4 := net.IPv4(1, 2, 3, 4)
+	ipv6 := net.ParseIP("::1")
+
+	testCases := map[string]struct {
+		record  librecords.Record
+		ip      net.IP
+		ipv4    net.IP
+		ipv6    net.IP
+		update  bool
+		message string
+	}{
+		"no matching IP": {
+			record: librecords.Record{
+				Settings: &testSettings{ipVersion: ipversion.IP6},
+			},
+			ipv4:    ipv4,
+			message: "no public ipv6 address found for example.com, skipping update",
+		},
+		"within cooldown and ban period": {
+			record: librecords.Record{
+				Settings: &testSettings{ipVersion: ipversion.IP4},
+				Status:   constants.SUCCESS,
+				History:  models.History{{IP: ipv4, Time: now}},
+				LastBan:  &now,
+			},
+			ipv4:   ipv4,
+			ipv6:   ipv6,
+			update: true,
+			message: "Forcing update of example.com to use 1.2.3.4, " +
+				"ignoring its cooldown, ban period, backoff and DNS resolution",
+		},
+		"backing off": {
+			record: librecords.Record{
+				Settings: &testSettings{ipVersion: ipversion.IP6},
+				Status:   constants.FAIL,
+				Backoff:  models.Backoff{Failures: 1, RetryTime: now.Add(time.Hour)},
+			},
+			ipv6:   ipv6,
+			update: true,
+			message: "Forcing update of example.com to use ::1, " +
+				"ignoring its cooldown, ban period, backoff and DNS resolution",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var logger logBuffer
+			update := shouldForceUpdateRecord(testCase.record,
+				testCase.ip, testCase.ipv4, testCase.ipv6, &logger)
+
+			assert.Equal(t, testCase.update, update)
+			if assert.Len(t, logger.lines, 1) {
+				assert.Equal(t, testCase.message, logger.lines[0].message)
+			}
+		})
+	}
+}
+
+func Test_Runner_updateRecord_disabled(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	ipv4 := net.IPv4(1, 2, 3, 4)
+	disabled := librecords.Record{
+		ID:            "a",
+		Settings:      &testSettings{ipVersion: ipversion.IP4},
+		Status:        constants.DISABLED,
+		ErrorCategory: "authentication",
+		Backoff:       models.Backoff{Failures: 1},
+	}
+	db := &testDatabase{records: map[string]librecords.Record{"a": disabled}}
+	logger := noopLogger{}
+	updater := NewUpdater(db, &http.Client{}, NewBackoff(0, 0),
+		func(string) {}, logger)
+	runner := NewRunner(db, updater, nil, &testIPGetter{ipv4: ipv4}, Periodic(time.Hour),
+		0, nil, 0, 1, 1, false, logger, nil, func() time.Time { return now })
+
+	errs := runner.updateRecord(context.Background(),
+		forceRecordRequest{id: "a", unconditional: false})
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], ErrRecordDisabled)
+	assert.Equal(t, constants.DISABLED, db.records["a"].Status)
+
+	errs = runner.updateRecord(context.Background(),
+		forceRecordRequest{id: "a", unconditional: true})
+	require.Empty(t, errs)
+
+	record := db.records["a"]
+	assert.Equal(t, constants.SUCCESS, record.Status)
+	assert.Empty(t, record.ErrorCategory)
+	assert.Equal(t, models.Backoff{}, record.Backoff)
+	assert.Equal(t, ipv4, record.History.GetCurrentIP())
+}
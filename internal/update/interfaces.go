@@ -5,7 +5,9 @@ import (
 	"net"
 	"time"
 
+	"github.com/qdm12/ddns-updater/internal/models"
 	"github.com/qdm12/ddns-updater/internal/records"
+	"github.com/qdm12/ddns-updater/internal/settings"
 )
 
 type PublicIPFetcher interface {
@@ -15,13 +17,24 @@ type PublicIPFetcher interface {
 }
 
 type UpdaterInterface interface {
-	Update(ctx context.Context, recordID uint, ip net.IP, now time.Time) (err error)
+	Update(ctx context.Context, recordID string, ip net.IP, now time.Time) (err error)
+}
+
+// Auditor appends entries to the audit log.
+type Auditor interface {
+	Append(entries []models.AuditEntry) (err error)
 }
 
 type Database interface {
-	Select(recordID uint) (record records.Record, err error)
+	Select(recordID string) (record records.Record, err error)
 	SelectAll() (records []records.Record)
-	Update(recordID uint, record records.Record) (err error)
+	Update(recordID string, record records.Record) (err error)
+	Reload(allSettings []settings.Settings) (err error)
+}
+
+// Scheduler returns the next time to check records after the time given.
+type Scheduler interface {
+	Next(after time.Time) time.Time
 }
 
 type LookupIPer interface {
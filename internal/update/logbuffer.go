@@ -0,0 +1,56 @@
+package update
+
+type logLevel uint8
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+type logLine struct {
+	level   logLevel
+	message string
+}
+
+// logBuffer is a Logger buffering log lines in memory, so logs
+// of records processed concurrently can be written in a
+// deterministic order. It is not safe for concurrent use.
+type logBuffer struct {
+	lines []logLine
+}
+
+func (b *logBuffer) Debug(s string) {
+	b.lines = append(b.lines, logLine{level: levelDebug, message: s})
+}
+
+func (b *logBuffer) Info(s string) {
+	b.lines = append(b.lines, logLine{level: levelInfo, message: s})
+}
+
+func (b *logBuffer) Warn(s string) {
+	b.lines = append(b.lines, logLine{level: levelWarn, message: s})
+}
+
+func (b *logBuffer) Error(s string) {
+	b.lines = append(b.lines, logLine{level: levelError, message: s})
+}
+
+// flush writes all the buffered log lines to the logger
+// and empties the buffer.
+func (b *logBuffer) flush(logger Logger) {
+	for _, line := range b.lines {
+		switch line.level {
+		case levelDebug:
+			logger.Debug(line.message)
+		case levelInfo:
+			logger.Info(line.message)
+		case levelWarn:
+			logger.Warn(line.message)
+		case levelError:
+			logger.Error(line.message)
+		}
+	}
+	b.lines = nil
+}
@@ -0,0 +1,260 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/constants"
+	"github.com/qdm12/ddns-updater/internal/models"
+	librecords "github.com/qdm12/ddns-updater/internal/records"
+)
+
+// Plan contains the actions the runner decided to take for
+// each record checked, given the public IP addresses found.
+type Plan struct {
+	Time    time.Time    `json:"time"`
+	IP      net.IP       `json:"ip,omitempty"`
+	IPv4    net.IP       `json:"ipv4,omitempty"`
+	IPv6    net.IP       `json:"ipv6,omitempty"`
+	Records []PlanRecord `json:"records"`
+	Errors  []string     `json:"errors,omitempty"`
+}
+
+// PlanRecord is the action decided for a single record.
+type PlanRecord struct {
+	ID        string          `json:"id"`
+	Provider  models.Provider `json:"provider"`
+	Domain    string          `json:"domain"`
+	Host      string          `json:"host"`
+	IPVersion string          `json:"ip_version"`
+	Action    PlanAction      `json:"action"`
+	// IP is the IP address to send to the provider,
+	// or to set as current IP address for the record.
+	IP net.IP `json:"ip,omitempty"`
+	// ResolvedIP is the IP address the record resolves to,
+	// and is nil if the record was not resolved.
+	ResolvedIP net.IP `json:"resolved_ip,omitempty"`
+	// Reasons are the log messages explaining the decision.
+	Reasons []string `json:"reasons,omitempty"`
+	// detectedIP is the public IP address matching the IP version
+	// of the record, and duration is the duration of the decision,
+	// both used for the audit log.
+	detectedIP net.IP
+	duration   time.Duration
+}
+
+// PlanAction is the action decided for a record.
+type PlanAction string
+
+const (
+	// PlanActionUpdate is to update the record at the provider.
+	PlanActionUpdate PlanAction = "update"
+	// PlanActionSetUpToDate is to mark a record seen for the first
+	// time as up to date, without sending anything to the provider.
+	PlanActionSetUpToDate PlanAction = "set up to date"
+	// PlanActionSkip is to leave the record as it is.
+	PlanActionSkip PlanAction = "skip"
+)
+
+// Plan returns the plan of actions the runner would take for all the
+// records now, without updating any record. The decisions are only
+// logged at the debug level, since they are returned in the plan.
+// It is safe to call it concurrently with Run.
+func (r *Runner) Plan(ctx context.Context) (plan Plan) {
+	plan, _ = r.makePlan(ctx, debugLogger{DebugLogger: r.logger}, r.db.SelectAll(),
+		r.timeNow(), r.ipv6Mask, allRecords, false)
+	return plan
+}
+
+// debugLogger is a Logger logging all messages at the debug level.
+type debugLogger struct {
+	DebugLogger
+}
+
+func (l debugLogger) Info(s string)  { l.Debug(s) }
+func (l debugLogger) Warn(s string)  { l.Debug(s) }
+func (l debugLogger) Error(s string) { l.Debug(s) }
+
+// makePlan fetches the public IP addresses and decides which
+// action to take for each selected record, logging the decisions
+// with the logger given. If unconditional is true, each selected
+// record is to be updated if a public IP address matching its IP
+// version is found.
+func (r *Runner) makePlan(ctx context.Context, logger Logger, records []librecords.Record,
+	now time.Time, ipv6Mask net.IPMask, selected recordSelector, unconditional bool) (
+	plan Plan, errors []error) {
+	plan.Time = now
+	doIP, doIPv4, doIPv6 := doIPVersion(records, now, selected)
+	logger.Debug(fmt.Sprintf("configured to fetch IP: v4 or v6: %t, v4: %t, v6: %t", doIP, doIPv4, doIPv6))
+	plan.IP, plan.IPv4, plan.IPv6, errors = r.getNewIPs(ctx, doIP, doIPv4, doIPv6, ipv6Mask)
+	r.setPublicIPs(plan.IP, plan.IPv4, plan.IPv6, now)
+	logger.Debug(fmt.Sprintf("your public IP address are: v4 or v6: %s, v4: %s, v6: %s",
+		plan.IP, plan.IPv4, plan.IPv6))
+	for _, err := range errors {
+		logger.Error(err.Error())
+		plan.Errors = append(plan.Errors, err.Error())
+	}
+
+	selectedRecords := make([]librecords.Record, 0, len(records))
+	for _, record := range records {
+		if selected(record, now) {
+			selectedRecords = append(selectedRecords, record)
+		}
+	}
+
+	shouldUpdate := make([]bool, len(selectedRecords))
+	resolvedIPs := make([]net.IP, len(selectedRecords))
+	durations := make([]time.Duration, len(selectedRecords))
+	logBuffers := make([]logBuffer, len(selectedRecords))
+	r.pool.run(providersOf(selectedRecords), func(i int) {
+		start := r.timeNow()
+		if unconditional {
+			shouldUpdate[i] = shouldForceUpdateRecord(selectedRecords[i],
+				plan.IP, plan.IPv4, plan.IPv6, &logBuffers[i])
+		} else {
+			shouldUpdate[i], resolvedIPs[i] = r.shouldUpdateRecord(ctx, selectedRecords[i],
+				plan.IP, plan.IPv4, plan.IPv6, now, ipv6Mask, &logBuffers[i])
+		}
+		durations[i] = r.timeNow().Sub(start)
+	})
+
+	plan.Records = make([]PlanRecord, len(selectedRecords))
+	for i, record := range selectedRecords {
+		detectedIP := getIPMatchingVersion(plan.IP, plan.IPv4, plan.IPv6, record.Settings.IPVersion())
+		planRecord := PlanRecord{
+			ID:         record.ID,
+			Provider:   record.Settings.Provider(),
+			Domain:     record.Settings.Domain(),
+			Host:       record.Settings.Host(),
+			IPVersion:  record.Settings.IPVersion().String(),
+			Action:     PlanActionSkip,
+			IP:         detectedIP,
+			ResolvedIP: resolvedIPs[i],
+			detectedIP: detectedIP,
+			duration:   durations[i],
+		}
+		for _, line := range logBuffers[i].lines {
+			planRecord.Reasons = append(planRecord.Reasons, line.message)
+		}
+		logBuffers[i].flush(logger)
+
+		switch {
+		case shouldUpdate[i]:
+			planRecord.Action = PlanActionUpdate
+		case record.Status == constants.UNSET && planRecord.IP != nil:
+			planRecord.Action = PlanActionSetUpToDate
+		default:
+			planRecord.IP = nil
+		}
+		plan.Records[i] = planRecord
+	}
+
+	return plan, errors
+}
+
+// applyPlan sets the initial status of records and updates
+// the records at their provider, as decided in the plan.
+// Each decision and its outcome is recorded in the audit log,
+// and the audit entries are returned as results.
+func (r *Runner) applyPlan(ctx context.Context, records []librecords.Record,
+	plan Plan) (results []models.AuditEntry, errors []error) {
+	idToRecord := make(map[string]librecords.Record, len(records))
+	for _, record := range records {
+		idToRecord[record.ID] = record
+	}
+
+	auditEntries := make([]models.AuditEntry, 0, len(plan.Records))
+	var updates []PlanRecord
+	var updateRecords []librecords.Record
+	for _, planRecord := range plan.Records {
+		switch planRecord.Action {
+		case PlanActionUpdate:
+			updates = append(updates, planRecord)
+			updateRecords = append(updateRecords, idToRecord[planRecord.ID])
+		case PlanActionSetUpToDate:
+			err := setInitialUpToDateStatus(r.db, planRecord.ID, planRecord.IP, plan.Time)
+			if err != nil {
+				errors = append(errors, err)
+				r.logger.Error(err.Error())
+			}
+			auditEntries = append(auditEntries, r.makeAuditEntry(plan.Time, planRecord,
+				planRecord.duration))
+		case PlanActionSkip:
+			auditEntries = append(auditEntries, r.makeAuditEntry(plan.Time, planRecord,
+				planRecord.duration))
+		}
+	}
+
+	updateErrors := make([]error, len(updates))
+	updateDurations := make([]time.Duration, len(updates))
+	logBuffers := make([]logBuffer, len(updates))
+	r.pool.run(providersOf(updateRecords), func(i int) {
+		update := updates[i]
+		logBuffers[i].Info("Updating record " + updateRecords[i].Settings.String() +
+			" (id " + update.ID + ") to use " + update.IP.String())
+		start := r.timeNow()
+		updateErrors[i] = r.updater.Update(ctx, update.ID, update.IP, start)
+		updateDurations[i] = r.timeNow().Sub(start)
+	})
+
+	for i, err := range updateErrors {
+		logBuffers[i].flush(r.logger)
+		if err != nil {
+			errors = append(errors, err)
+			r.logger.Error(err.Error())
+		}
+		auditEntries = append(auditEntries, r.makeAuditEntry(plan.Time, updates[i],
+			updateDurations[i]))
+	}
+
+	if r.auditor != nil {
+		err := r.auditor.Append(auditEntries)
+		if err != nil {
+			r.logger.Error("writing audit log: " + err.Error())
+		}
+	}
+
+	return auditEntries, errors
+}
+
+// makeAuditEntry returns the audit log entry for the record decision
+// given, using the record state after the action was taken.
+func (r *Runner) makeAuditEntry(now time.Time, planRecord PlanRecord,
+	duration time.Duration) (entry models.AuditEntry) {
+	entry = models.AuditEntry{
+		Time:       now,
+		RecordID:   planRecord.ID,
+		Provider:   planRecord.Provider,
+		Domain:     planRecord.Domain,
+		Host:       planRecord.Host,
+		DetectedIP: planRecord.detectedIP,
+		ResolvedIP: planRecord.ResolvedIP,
+		Action:     string(planRecord.Action),
+		Duration:   duration,
+	}
+	if len(planRecord.Reasons) > 0 {
+		entry.Response = planRecord.Reasons[len(planRecord.Reasons)-1]
+	}
+
+	record, err := r.db.Select(planRecord.ID)
+	if err != nil { // record removed by a reload
+		return entry
+	}
+	entry.Status = record.Status
+	if planRecord.Action == PlanActionUpdate {
+		entry.Response = record.Message
+		entry.ErrorCategory = string(record.ErrorCategory)
+	}
+	return entry
+}
+
+func (r *Runner) logPlan(plan Plan) {
+	for _, planRecord := range plan.Records {
+		if planRecord.Action == PlanActionUpdate {
+			r.logger.Info(fmt.Sprintf("[dry run] would update %s %s (%s, id %s) with IP %s",
+				planRecord.Provider, planRecord.Host, planRecord.Domain, planRecord.ID, planRecord.IP))
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package update
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/constants"
+	"github.com/qdm12/ddns-updater/internal/models"
+	librecords "github.com/qdm12/ddns-updater/internal/records"
+	"github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testResolver struct {
+	hostToIPs map[string][]net.IP
+}
+
+func (r *testResolver) LookupIP(_ context.Context, _, host string) (ips []net.IP, err error) {
+	return r.hostToIPs[host], nil
+}
+
+func Test_Runner_makePlan(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	publicIP := net.IPv4(1, 2, 3, 4)
+	oldIP := net.IPv4(5, 6, 7, 8)
+	resolver := &testResolver{hostToIPs: map[string][]net.IP{
+		"current.example.com": {publicIP},
+		"old.example.com":     {oldIP},
+	}}
+
+	testCases := map[string]struct {
+		record        librecords.Record
+		unconditional bool
+		action        PlanAction
+		ip            net.IP
+		resolvedIP    net.IP
+	}{
+		"resolves to the public IP": {
+			record: librecords.Record{Status: constants.SUCCESS,
+				Settings: &testSettings{name: "current.example.com", ipVersion: ipversion.IP4}},
+			action:     PlanActionSkip,
+			resolvedIP: publicIP,
+		},
+		"resolves to another IP": {
+			record: librecords.Record{Status: constants.SUCCESS,
+				Settings: &testSettings{name: "old.example.com", ipVersion: ipversion.IP4}},
+			action:     PlanActionUpdate,
+			ip:         publicIP,
+			resolvedIP: oldIP,
+		},
+		"first seen resolving to the public IP": {
+			record: librecords.Record{Status: constants.UNSET,
+				Settings: &testSettings{name: "current.example.com", ipVersion: ipversion.IP4}},
+			action:     PlanActionSetUpToDate,
+			ip:         publicIP,
+			resolvedIP: publicIP,
+		},
+		"proxied with another last IP": {
+			record: librecords.Record{Status: constants.SUCCESS,
+				Settings: &testSettings{ipVersion: ipversion.IP4, proxied: true},
+				History:  models.History{{IP: oldIP}}},
+			action: PlanActionUpdate,
+			ip:     publicIP,
+		},
+		"disabled": {
+			record: librecords.Record{Status: constants.DISABLED,
+				Settings: &testSettings{name: "old.example.com", ipVersion: ipversion.IP4}},
+			action: PlanActionSkip,
+		},
+		"disabled unconditional": {
+			record: librecords.Record{Status: constants.DISABLED,
+				Settings: &testSettings{name: "current.example.com", ipVersion: ipversion.IP4}},
+			unconditional: true,
+			action:        PlanActionUpdate,
+			ip:            publicIP,
+		},
+		"backing off": {
+			record: librecords.Record{Status: constants.FAIL,
+				Settings: &testSettings{name: "old.example.com", ipVersion: ipversion.IP4},
+				Backoff:  models.Backoff{Failures: 1, RetryTime: now.Add(time.Minute)}},
+			action: PlanActionSkip,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			testCase.record.ID = "a"
+			db := &testDatabase{records: map[string]librecords.Record{"a": testCase.record}}
+			runner := NewRunner(db, nil, nil, &testIPGetter{ipv4: publicIP}, Periodic(time.Hour),
+				0, nil, 0, 1, 1, false, noopLogger{}, resolver, func() time.Time { return now })
+
+			plan, errs := runner.makePlan(context.Background(), noopLogger{}, db.SelectAll(),
+				now, nil, allRecords, testCase.unconditional)
+
+			require.Empty(t, errs)
+			assert.Equal(t, now, plan.Time)
+			assert.Equal(t, publicIP, plan.IPv4)
+			require.Len(t, plan.Records, 1)
+			planRecord := plan.Records[0]
+			assert.Equal(t, "a", planRecord.ID)
+			assert.Equal(t, testCase.action, planRecord.Action)
+			assert.Equal(t, testCase.ip, planRecord.IP)
+			assert.Equal(t, testCase.resolvedIP, planRecord.ResolvedIP)
+			assert.Zero(t, db.updates)
+		})
+	}
+}
+
+func Test_Runner_Plan(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	publicIP := net.IPv4(1, 2, 3, 4)
+	updates := new(atomic.Int32)
+	db := &testDatabase{records: map[string]librecords.Record{
+		"a": {ID: "a", Status: constants.SUCCESS, Settings: &testSettings{
+			ipVersion: ipversion.IP4, proxied: true, updates: updates}},
+	}}
+	var logger logBuffer
+	runner := NewRunner(db, nil, nil, &testIPGetter{ipv4: publicIP}, Periodic(time.Hour),
+		0, nil, 0, 1, 1, false, &logger, nil, func() time.Time { return now })
+
+	plan := runner.Plan(context.Background())
+
+	require.Len(t, plan.Records, 1)
+	assert.Equal(t, PlanActionUpdate, plan.Records[0].Action)
+	assert.NotEmpty(t, plan.Records[0].Reasons)
+	assert.Zero(t, updates.Load())
+	assert.Zero(t, db.updates)
+	require.NotEmpty(t, logger.lines)
+	for _, line := range logger.lines {
+		assert.Equal(t, levelDebug, line.level, line.message)
+	}
+}
+
+func Test_Runner_dryRun(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	publicIP := net.IPv4(1, 2, 3, 4)
+	updates := new(atomic.Int32)
+	db := &testDatabase{records: map[string]librecords.Record{
+		"unset": {ID: "unset", Status: constants.UNSET, Settings: &testSettings{
+			ipVersion: ipversion.IP4, proxied: true, updates: updates}},
+		"changed": {ID: "changed", Status: constants.SUCCESS, Settings: &testSettings{
+			ipVersion: ipversion.IP4, proxied: true, updates: updates},
+			History: models.History{{IP: net.IPv4(5, 6, 7, 8)}}},
+		"disabled": {ID: "disabled", Status: constants.DISABLED, Settings: &testSettings{
+			ipVersion: ipversion.IP4, proxied: true, updates: updates}},
+	}}
+	const dryRun = true
+	updater := NewUpdater(db, &http.Client{}, NewBackoff(0, 0), func(string) {}, noopLogger{})
+	runner := NewRunner(db, updater, nil, &testIPGetter{ipv4: publicIP}, Periodic(time.Hour),
+		0, nil, 0, 1, 1, dryRun, noopLogger{}, nil, func() time.Time { return now })
+	ctx := context.Background()
+
+	errs := runner.UpdateOnce(ctx)
+	assert.Empty(t, errs)
+
+	errs = runner.updateRecord(ctx, forceRecordRequest{id: "changed"})
+	assert.Empty(t, errs)
+
+	errs = runner.updateRecord(ctx, forceRecordRequest{id: "disabled", unconditional: true})
+	assert.Empty(t, errs)
+
+	assert.Zero(t, updates.Load())
+	assert.Zero(t, db.updates)
+	assert.Equal(t, constants.DISABLED, db.records["disabled"].Status)
+}
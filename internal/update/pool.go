@@ -0,0 +1,57 @@
+package update
+
+import (
+	"sync"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+)
+
+// workerPool runs work concurrently, bounded by a total
+// number of workers and a number of workers per provider.
+type workerPool struct {
+	workers         uint
+	providerWorkers uint
+}
+
+func newWorkerPool(workers, providerWorkers uint) workerPool {
+	if workers == 0 {
+		workers = 1
+	}
+	if providerWorkers == 0 {
+		providerWorkers = 1
+	}
+	return workerPool{
+		workers:         workers,
+		providerWorkers: providerWorkers,
+	}
+}
+
+// run runs work(i) for each index i of the providers slice, where
+// providers[i] is the provider targeted by the work at index i.
+// It blocks until all the work is done.
+func (p workerPool) run(providers []models.Provider, work func(i int)) {
+	workersSemaphore := make(chan struct{}, p.workers)
+	providerSemaphores := make(map[models.Provider]chan struct{})
+	for _, provider := range providers {
+		if _, ok := providerSemaphores[provider]; !ok {
+			providerSemaphores[provider] = make(chan struct{}, p.providerWorkers)
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(providers))
+	for i, provider := range providers {
+		providerSemaphore := providerSemaphores[provider]
+		go func(i int) {
+			defer wg.Done()
+			// Acquire the provider slot first to not hold a worker
+			// slot while waiting on another record of the same provider.
+			providerSemaphore <- struct{}{}
+			workersSemaphore <- struct{}{}
+			work(i)
+			<-workersSemaphore
+			<-providerSemaphore
+		}(i)
+	}
+	wg.Wait()
+}
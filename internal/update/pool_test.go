@@ -0,0 +1,91 @@
+package update
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_workerPool_run(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		workers            uint
+		providerWorkers    uint
+		providers          []models.Provider
+		maxRunning         int
+		maxRunningProvider int
+	}{
+		"no work": {
+			workers:         2,
+			providerWorkers: 1,
+		},
+		"single provider": {
+			workers:            4,
+			providerWorkers:    1,
+			providers:          []models.Provider{"a", "a", "a"},
+			maxRunning:         1,
+			maxRunningProvider: 1,
+		},
+		"bounded by workers": {
+			workers:            2,
+			providerWorkers:    2,
+			providers:          []models.Provider{"a", "a", "b", "b", "c", "c"},
+			maxRunning:         2,
+			maxRunningProvider: 2,
+		},
+		"bounded by provider workers": {
+			workers:            8,
+			providerWorkers:    2,
+			providers:          []models.Provider{"a", "a", "a", "a", "b"},
+			maxRunning:         3,
+			maxRunningProvider: 2,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pool := newWorkerPool(testCase.workers, testCase.providerWorkers)
+
+			var mutex sync.Mutex
+			running, maxRunning := 0, 0
+			runningProvider := make(map[models.Provider]int)
+			maxRunningProvider := 0
+			done := make([]bool, len(testCase.providers))
+
+			pool.run(testCase.providers, func(i int) {
+				provider := testCase.providers[i]
+				mutex.Lock()
+				running++
+				runningProvider[provider]++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				if runningProvider[provider] > maxRunningProvider {
+					maxRunningProvider = runningProvider[provider]
+				}
+				mutex.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				mutex.Lock()
+				running--
+				runningProvider[provider]--
+				done[i] = true
+				mutex.Unlock()
+			})
+
+			assert.LessOrEqual(t, maxRunning, testCase.maxRunning)
+			assert.LessOrEqual(t, maxRunningProvider, testCase.maxRunningProvider)
+			for i := range done {
+				assert.True(t, done[i])
+			}
+		})
+	}
+}
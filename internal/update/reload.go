@@ -0,0 +1,69 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/settings"
+)
+
+type reloadRequest struct {
+	allSettings []settings.Settings
+	done        chan<- error
+}
+
+// Reload reconciles the records with the settings given. It is done in
+// the Run goroutine so it does not interfere with an update in progress.
+func (r *Runner) Reload(ctx context.Context, allSettings []settings.Settings) (err error) {
+	done := make(chan error, 1)
+	select {
+	case r.reload <- reloadRequest{allSettings: allSettings, done: done}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	return err
+}
+
+// reloadRecords reloads the records in the database and keeps the next
+// check time of the records kept with unchanged settings. Records added
+// or with changed settings are checked right away.
+func (r *Runner) reloadRecords(allSettings []settings.Settings) (err error) {
+	previousSettings := make(map[string]settings.Settings, len(r.nextChecks))
+	for _, record := range r.db.SelectAll() {
+		previousSettings[record.ID] = record.Settings
+	}
+
+	err = r.db.Reload(allSettings)
+	if err != nil {
+		return err
+	}
+
+	kept := 0
+	nextChecks := make(map[string]time.Time, len(allSettings))
+	for _, record := range r.db.SelectAll() {
+		previous, ok := previousSettings[record.ID]
+		if !ok {
+			continue
+		}
+		kept++
+		nextCheck, ok := r.nextChecks[record.ID]
+		if !ok || settings.Fingerprint(previous) != settings.Fingerprint(record.Settings) {
+			continue
+		}
+		nextChecks[record.ID] = nextCheck
+	}
+	r.stateMutex.Lock()
+	r.nextChecks = nextChecks
+	r.stateMutex.Unlock()
+
+	r.logger.Info("reloaded " + fmt.Sprint(len(allSettings)) + " records, keeping " +
+		fmt.Sprint(kept) + " existing records")
+	return nil
+}
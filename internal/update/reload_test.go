@@ -0,0 +1,86 @@
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	librecords "github.com/qdm12/ddns-updater/internal/records"
+	"github.com/qdm12/ddns-updater/internal/settings"
+	"github.com/qdm12/ddns-updater/internal/settings/constants"
+	"github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (db *testDatabase) Reload(allSettings []settings.Settings) (err error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	records := make(map[string]librecords.Record, len(allSettings))
+	for _, s := range allSettings {
+		id := settings.ID(s)
+		record := db.records[id]
+		record.ID, record.Settings = id, s
+		records[id] = record
+	}
+	db.records = records
+	return nil
+}
+
+// newOVHSettings returns new OVH settings, which cannot be
+// compared with reflect.DeepEqual since they contain a function.
+func newOVHSettings(t *testing.T, password string) settings.Settings {
+	t.Helper()
+	raw := `{"provider":"ovh","domain":"example.com","host":"@",` +
+		`"username":"user","password":"` + password + `"}`
+	s, err := settings.New(constants.OVH, json.RawMessage(raw),
+		"example.com", "@", ipversion.IP4)
+	require.NoError(t, err)
+	return settings.WithCommon(s, settings.Common{Raw: raw})
+}
+
+func Test_Runner_reloadRecords(t *testing.T) {
+	t.Parallel()
+
+	nextCheck := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	ovhSettings := newOVHSettings(t, "password")
+	id := settings.ID(ovhSettings)
+	db := &testDatabase{records: map[string]librecords.Record{
+		id: {ID: id, Settings: ovhSettings},
+	}}
+	runner := NewRunner(db, nil, nil, nil, Periodic(time.Hour),
+		0, nil, 0, 1, 1, false, noopLogger{}, nil, time.Now)
+	runner.nextChecks[id] = nextCheck
+
+	err := runner.reloadRecords([]settings.Settings{newOVHSettings(t, "password")})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{id: nextCheck}, runner.nextChecks)
+
+	err = runner.reloadRecords([]settings.Settings{newOVHSettings(t, "changed")})
+	require.NoError(t, err)
+	assert.Empty(t, runner.nextChecks)
+}
+
+func Test_Runner_Run_callerGaveUp(t *testing.T) {
+	t.Parallel()
+
+	db := &testDatabase{records: map[string]librecords.Record{}}
+	runner := NewRunner(db, nil, nil, nil, Periodic(time.Hour),
+		0, nil, 0, 1, 1, false, noopLogger{}, nil, time.Now)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go runner.Run(ctx, done)
+
+	// Simulate callers giving up without reading their result.
+	runner.reload <- reloadRequest{done: make(chan error, 1)}
+	runner.forceRecord <- forceRecordRequest{id: "a", result: make(chan []error, 1)}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.Fail(t, "Run did not exit after its context was canceled")
+	}
+}
@@ -3,7 +3,9 @@ package update
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/qdm12/ddns-updater/internal/constants"
@@ -13,34 +15,63 @@ import (
 )
 
 type Runner struct {
-	period      time.Duration
-	db          Database
-	updater     UpdaterInterface
-	force       chan struct{}
-	forceResult chan []error
+	schedule Scheduler
+	jitter   time.Duration
+	db       Database
+	updater  UpdaterInterface
+	auditor  Auditor
+	// force, forceRecord and reload receive requests for the Run
+	// goroutine, each with its own result channel buffered to 1, so
+	// Run never blocks sending a result to a caller which gave up.
+	force       chan chan<- forceResult
+	forceRecord chan forceRecordRequest
+	reload      chan reloadRequest
+	dryRun      bool
 	ipv6Mask    net.IPMask
 	cooldown    time.Duration
 	resolver    LookupIPer
 	ipGetter    PublicIPFetcher
+	pool        workerPool
+	// nextChecks is only written in the Run goroutine, with stateMutex
+	// locked, such that it can be read in the Run goroutine without lock.
+	nextChecks map[string]time.Time
+	nextRun    time.Time
+	lastRun    time.Time
+	// previousRun is the time of the pass before the last one, and is
+	// only accessed in the Run goroutine.
+	previousRun time.Time
+	publicIPs   PublicIPs
+	stateMutex  sync.RWMutex
 	logger      Logger
 	timeNow     func() time.Time
+	randInt63n  func(n int64) int64
 }
 
-func NewRunner(db Database, updater UpdaterInterface, ipGetter PublicIPFetcher,
-	period time.Duration, ipv6Mask net.IPMask, cooldown time.Duration,
-	logger Logger, resolver LookupIPer, timeNow func() time.Time) *Runner {
+// NewRunner creates a new runner. The auditor can be nil
+// to disable the audit log.
+func NewRunner(db Database, updater UpdaterInterface, auditor Auditor, ipGetter PublicIPFetcher,
+	schedule Scheduler, jitter time.Duration, ipv6Mask net.IPMask, cooldown time.Duration,
+	workers, providerWorkers uint, dryRun bool, logger Logger, resolver LookupIPer,
+	timeNow func() time.Time) *Runner {
 	return &Runner{
-		period:      period,
+		schedule:    schedule,
+		jitter:      jitter,
 		db:          db,
 		updater:     updater,
-		force:       make(chan struct{}),
-		forceResult: make(chan []error),
+		auditor:     auditor,
+		force:       make(chan chan<- forceResult),
+		forceRecord: make(chan forceRecordRequest),
+		reload:      make(chan reloadRequest),
+		dryRun:      dryRun,
 		ipv6Mask:    ipv6Mask,
 		cooldown:    cooldown,
 		resolver:    resolver,
 		ipGetter:    ipGetter,
+		pool:        newWorkerPool(workers, providerWorkers),
+		nextChecks:  make(map[string]time.Time),
 		logger:      logger,
 		timeNow:     timeNow,
+		randInt63n:  rand.Int63n, //nolint:gosec
 	}
 }
 
@@ -70,8 +101,12 @@ func (r *Runner) lookupIPs(ctx context.Context, hostname string) (ipv4 net.IP, i
 	return ipv4, ipv6, nil
 }
 
-func doIPVersion(records []librecords.Record) (doIP, doIPv4, doIPv6 bool) {
+func doIPVersion(records []librecords.Record, now time.Time, selected recordSelector) (
+	doIP, doIPv4, doIPv6 bool) {
 	for _, record := range records {
+		if !selected(record, now) {
+			continue
+		}
 		switch record.Settings.IPVersion() {
 		case ipversion.IP4or6:
 			doIP = true
@@ -115,79 +150,90 @@ func (r *Runner) getNewIPs(ctx context.Context, doIP, doIPv4, doIPv6 bool, ipv6M
 	return ip, ipv4, ipv6, errors
 }
 
-func (r *Runner) getRecordIDsToUpdate(ctx context.Context, records []librecords.Record,
-	ip, ipv4, ipv6 net.IP, now time.Time, ipv6Mask net.IPMask) (recordIDs map[uint]struct{}) {
-	recordIDs = make(map[uint]struct{})
+func providersOf(records []librecords.Record) (providers []models.Provider) {
+	providers = make([]models.Provider, len(records))
 	for i, record := range records {
-		if shouldUpdate := r.shouldUpdateRecord(ctx, record, ip, ipv4, ipv6, now, ipv6Mask); shouldUpdate {
-			id := uint(i)
-			recordIDs[id] = struct{}{}
-		}
+		providers[i] = record.Settings.Provider()
 	}
-	return recordIDs
+	return providers
 }
 
+// shouldUpdateRecord returns true if the record should be updated, and
+// the IP address the record resolves to if it was resolved.
 func (r *Runner) shouldUpdateRecord(ctx context.Context, record librecords.Record,
-	ip, ipv4, ipv6 net.IP, now time.Time, ipv6Mask net.IPMask) (update bool) {
-	isWithinBanPeriod := record.LastBan != nil && now.Sub(*record.LastBan) < time.Hour
-	isWithinCooldown := now.Sub(record.History.GetSuccessTime()) < r.cooldown
+	ip, ipv4, ipv6 net.IP, now time.Time, ipv6Mask net.IPMask, logger Logger) (
+	update bool, resolvedIP net.IP) {
+	if record.Status == constants.DISABLED {
+		domain := record.Settings.BuildDomainName()
+		logger.Debug("record " + domain + " is disabled, skipping update")
+		return false, nil
+	}
+
+	isWithinBanPeriod := record.LastBan != nil && now.Sub(*record.LastBan) < banPeriod
+	isWithinCooldown := now.Sub(record.History.GetSuccessTime()) < r.cooldownOf(record)
 	if isWithinBanPeriod || isWithinCooldown {
 		domain := record.Settings.BuildDomainName()
-		r.logger.Debug("record " + domain + " is within ban period or cooldown period, skipping update")
-		return false
+		logger.Debug("record " + domain + " is within ban period or cooldown period, skipping update")
+		return false, nil
+	} else if record.Backoff.IsWithin(now) {
+		domain := record.Settings.BuildDomainName()
+		logger.Debug("record " + domain + " is backing off until " +
+			record.Backoff.RetryTime.Format(time.RFC3339) + ", skipping update")
+		return false, nil
 	}
 
 	hostname := record.Settings.BuildDomainName()
 	ipVersion := record.Settings.IPVersion()
 	if record.Settings.Proxied() {
 		lastIP := record.History.GetCurrentIP() // can be nil
-		return r.shouldUpdateRecordNoLookup(hostname, ipVersion, lastIP, ip, ipv4, ipv6)
+		update = r.shouldUpdateRecordNoLookup(hostname, ipVersion, lastIP, ip, ipv4, ipv6, logger)
+		return update, nil
 	}
-	return r.shouldUpdateRecordWithLookup(ctx, hostname, ipVersion, ip, ipv4, ipv6, ipv6Mask)
+	return r.shouldUpdateRecordWithLookup(ctx, hostname, ipVersion, ip, ipv4, ipv6, ipv6Mask, logger)
 }
 
 func (r *Runner) shouldUpdateRecordNoLookup(hostname string, ipVersion ipversion.IPVersion,
-	lastIP, ip, ipv4, ipv6 net.IP) (update bool) {
+	lastIP, ip, ipv4, ipv6 net.IP, logger Logger) (update bool) {
 	switch ipVersion {
 	case ipversion.IP4or6:
 		if ip != nil && !ip.Equal(lastIP) {
-			r.logger.Info("Last IP address stored for " + hostname +
+			logger.Info("Last IP address stored for " + hostname +
 				" is " + lastIP.String() + " and your IP address is " + ip.String())
 			return true
 		}
-		r.logger.Debug("Last IP address stored for " + hostname + " is " +
+		logger.Debug("Last IP address stored for " + hostname + " is " +
 			lastIP.String() + " and your IP address is " + ip.String() + ", skipping update")
 	case ipversion.IP4:
 		if ipv4 != nil && !ipv4.Equal(lastIP) {
-			r.logger.Info("Last IPv4 address stored for " + hostname +
+			logger.Info("Last IPv4 address stored for " + hostname +
 				" is " + lastIP.String() + " and your IPv4 address is " + ip.String())
 			return true
 		}
-		r.logger.Debug("Last IPv4 address stored for " + hostname + " is " +
+		logger.Debug("Last IPv4 address stored for " + hostname + " is " +
 			lastIP.String() + " and your IPv4 address is " + ip.String() + ", skipping update")
 	case ipversion.IP6:
 		if ipv6 != nil && !ipv6.Equal(lastIP) {
-			r.logger.Info("Last IPv6 address stored for " + hostname +
+			logger.Info("Last IPv6 address stored for " + hostname +
 				" is " + lastIP.String() + " and your IPv6 address is " + ip.String())
 			return true
 		}
-		r.logger.Debug("Last IPv6 address stored for " + hostname + " is " +
+		logger.Debug("Last IPv6 address stored for " + hostname + " is " +
 			lastIP.String() + " and your IPv6 address is " + ip.String() + ", skipping update")
 	}
 	return false
 }
 
 func (r *Runner) shouldUpdateRecordWithLookup(ctx context.Context, hostname string, ipVersion ipversion.IPVersion,
-	ip, ipv4, ipv6 net.IP, ipv6Mask net.IPMask) (update bool) {
+	ip, ipv4, ipv6 net.IP, ipv6Mask net.IPMask, logger Logger) (update bool, resolvedIP net.IP) {
 	const tries = 5
 	recordIPv4, recordIPv6, err := r.lookupIPsResilient(ctx, hostname, tries)
 	if err != nil {
 		ctxErr := ctx.Err()
 		if ctxErr != nil {
-			r.logger.Warn("DNS resolution of " + hostname + ": " + ctxErr.Error())
-			return false
+			logger.Warn("DNS resolution of " + hostname + ": " + ctxErr.Error())
+			return false, nil
 		}
-		r.logger.Warn("cannot DNS resolve " + hostname + " after " +
+		logger.Warn("cannot DNS resolve " + hostname + " after " +
 			fmt.Sprint(tries) + " tries: " + err.Error()) // update anyway
 	}
 
@@ -202,30 +248,33 @@ func (r *Runner) shouldUpdateRecordWithLookup(ctx context.Context, hostname stri
 			recordIP = recordIPv6
 		}
 		if ip != nil && !ip.Equal(recordIPv4) && !ip.Equal(recordIPv6) {
-			r.logger.Info("IP address of " + hostname + " is " + recordIP.String() +
+			logger.Info("IP address of " + hostname + " is " + recordIP.String() +
 				" and your IP address is " + ip.String())
-			return true
+			return true, recordIP
 		}
-		r.logger.Debug("IP address of " + hostname + " is " + recordIP.String() +
+		logger.Debug("IP address of " + hostname + " is " + recordIP.String() +
 			" and your IP address is " + ip.String() + ", skipping update")
+		return false, recordIP
 	case ipversion.IP4:
 		if ipv4 != nil && !ipv4.Equal(recordIPv4) {
-			r.logger.Info("IPv4 address of " + hostname + " is " + recordIPv4.String() +
+			logger.Info("IPv4 address of " + hostname + " is " + recordIPv4.String() +
 				" and your IPv4 address is " + ipv4.String())
-			return true
+			return true, recordIPv4
 		}
-		r.logger.Debug("IPv4 address of " + hostname + " is " + recordIPv4.String() +
+		logger.Debug("IPv4 address of " + hostname + " is " + recordIPv4.String() +
 			" and your IPv4 address is " + ipv4.String() + ", skipping update")
+		return false, recordIPv4
 	case ipversion.IP6:
 		if ipv6 != nil && !ipv6.Equal(recordIPv6) {
-			r.logger.Info("IPv6 address of " + hostname + " is " + recordIPv6.String() +
+			logger.Info("IPv6 address of " + hostname + " is " + recordIPv6.String() +
 				" and your IPv6 address is " + ipv6.String())
-			return true
+			return true, recordIPv6
 		}
-		r.logger.Debug("IPv6 address of " + hostname + " is " + recordIPv6.String() +
+		logger.Debug("IPv6 address of " + hostname + " is " + recordIPv6.String() +
 			" and your IPv6 address is " + ipv6.String() + ", skipping update")
+		return false, recordIPv6
 	}
-	return false
+	return false, nil
 }
 
 func getIPMatchingVersion(ip, ipv4, ipv6 net.IP, ipVersion ipversion.IPVersion) net.IP {
@@ -240,7 +289,7 @@ func getIPMatchingVersion(ip, ipv4, ipv6 net.IP, ipVersion ipversion.IPVersion)
 	return nil
 }
 
-func setInitialUpToDateStatus(db Database, id uint, updateIP net.IP, now time.Time) error {
+func setInitialUpToDateStatus(db Database, id string, updateIP net.IP, now time.Time) error {
 	record, err := db.Select(id)
 	if err != nil {
 		return err
@@ -256,69 +305,88 @@ func setInitialUpToDateStatus(db Database, id uint, updateIP net.IP, now time.Ti
 	return db.Update(id, record)
 }
 
-func (r *Runner) updateNecessary(ctx context.Context, ipv6Mask net.IPMask) (errors []error) {
+// updateNecessary checks and updates the selected records, and returns
+// the decision and outcome for each record, which are nil in dry run mode.
+// If unconditional is true, the selected records are updated regardless of
+// their ban period, cooldown, backoff and DNS resolution.
+func (r *Runner) updateNecessary(ctx context.Context, ipv6Mask net.IPMask,
+	selected recordSelector, unconditional bool) (results []models.AuditEntry, errors []error) {
 	records := r.db.SelectAll()
-	doIP, doIPv4, doIPv6 := doIPVersion(records)
-	r.logger.Debug(fmt.Sprintf("configured to fetch IP: v4 or v6: %t, v4: %t, v6: %t", doIP, doIPv4, doIPv6))
-	ip, ipv4, ipv6, errors := r.getNewIPs(ctx, doIP, doIPv4, doIPv6, ipv6Mask)
-	r.logger.Debug(fmt.Sprintf("your public IP address are: v4 or v6: %s, v4: %s, v6: %s", ip, ipv4, ipv6))
-	for _, err := range errors {
-		r.logger.Error(err.Error())
-	}
-
 	now := r.timeNow()
-	recordIDs := r.getRecordIDsToUpdate(ctx, records, ip, ipv4, ipv6, now, ipv6Mask)
+	r.stateMutex.Lock()
+	r.previousRun, r.lastRun = r.lastRun, now
+	r.stateMutex.Unlock()
+	defer r.setNextChecks(records, now, selected)
 
-	for i, record := range records {
-		id := uint(i)
-		_, requireUpdate := recordIDs[id]
-		if requireUpdate || record.Status != constants.UNSET {
-			continue
-		}
-		updateIP := getIPMatchingVersion(ip, ipv4, ipv6, record.Settings.IPVersion())
-		err := setInitialUpToDateStatus(r.db, id, updateIP, now)
-		if err != nil {
-			errors = append(errors, err)
-			r.logger.Error(err.Error())
-		}
-	}
-	for id := range recordIDs {
-		record := records[id]
-		updateIP := getIPMatchingVersion(ip, ipv4, ipv6, record.Settings.IPVersion())
-		r.logger.Info("Updating record " + record.Settings.String() + " to use " + updateIP.String())
-		err := r.updater.Update(ctx, id, updateIP, r.timeNow())
-		if err != nil {
-			errors = append(errors, err)
-			r.logger.Error(err.Error())
-		}
+	plan, errors := r.makePlan(ctx, r.logger, records, now, ipv6Mask, selected, unconditional)
+	if r.dryRun {
+		r.logPlan(plan)
+		return nil, errors
 	}
 
-	return errors
+	results, applyErrors := r.applyPlan(ctx, records, plan)
+	return results, append(errors, applyErrors...)
 }
 
 func (r *Runner) Run(ctx context.Context, done chan<- struct{}) {
 	defer close(done)
-	ticker := time.NewTicker(r.period)
+	r.setNextChecks(r.db.SelectAll(), r.timeNow(), allRecords)
+
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer r.setNextRun(time.Time{})
 	for {
+		now := r.timeNow()
+		nextRun := r.nextRunTime(now)
+		r.setNextRun(nextRun)
+		timer.Reset(nextRun.Sub(now))
 		select {
-		case <-ticker.C:
-			r.updateNecessary(ctx, r.ipv6Mask)
-		case <-r.force:
-			r.forceResult <- r.updateNecessary(ctx, r.ipv6Mask)
+		case <-timer.C:
+			r.updateNecessary(ctx, r.ipv6Mask, r.isDue, false)
+		case result := <-r.force:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			results, errs := r.updateNecessary(ctx, r.ipv6Mask, allRecords, false)
+			result <- forceResult{results: results, errs: errs}
+		case request := <-r.forceRecord:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			request.result <- r.updateRecord(ctx, request)
+		case request := <-r.reload:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			request.done <- r.reloadRecords(request.allSettings)
 		case <-ctx.Done():
-			ticker.Stop()
+			timer.Stop()
 			return
 		}
 	}
 }
 
-func (r *Runner) ForceUpdate(ctx context.Context) (errs []error) {
-	r.force <- struct{}{}
+// UpdateOnce checks and updates all the records once, without
+// the Run loop. It must not be called concurrently with Run.
+func (r *Runner) UpdateOnce(ctx context.Context) (errs []error) {
+	_, errs = r.updateNecessary(ctx, r.ipv6Mask, allRecords, false)
+	return errs
+}
 
+// ForceUpdate checks and updates all the records right away, and returns
+// the decision and outcome for each record, which are nil in dry run mode.
+func (r *Runner) ForceUpdate(ctx context.Context) (results []models.AuditEntry, errs []error) {
+	resultCh := make(chan forceResult, 1)
 	select {
-	case errs = <-r.forceResult:
+	case r.force <- resultCh:
 	case <-ctx.Done():
-		errs = []error{ctx.Err()}
+		return nil, []error{ctx.Err()}
+	}
+
+	select {
+	case result := <-resultCh:
+		return result.results, result.errs
+	case <-ctx.Done():
+		return nil, []error{ctx.Err()}
 	}
-	return errs
 }
@@ -0,0 +1,131 @@
+package update
+
+import (
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/constants"
+	librecords "github.com/qdm12/ddns-updater/internal/records"
+	"github.com/qdm12/ddns-updater/internal/settings"
+)
+
+// recordSelector returns true if the record should be
+// considered for an update in the current pass.
+type recordSelector func(record librecords.Record, now time.Time) bool
+
+func allRecords(librecords.Record, time.Time) bool { return true }
+
+// retryDue returns true if the record failed and its backoff retry time
+// is reached since the previous pass, so a record is retried only once
+// if its retry time does not change.
+func retryDue(record librecords.Record, previousRun, now time.Time) bool {
+	retryTime := record.Backoff.RetryTime
+	return record.Status == constants.FAIL && retryTime.After(previousRun) &&
+		!record.Backoff.IsWithin(now)
+}
+
+// isDue selects records whose scheduled check time is reached,
+// or whose backoff retry time is reached since the previous pass.
+func (r *Runner) isDue(record librecords.Record, now time.Time) bool {
+	nextCheck, ok := r.nextChecks[record.ID]
+	switch {
+	case !ok: // never checked
+		return true
+	case !nextCheck.IsZero() && !now.Before(nextCheck):
+		return true
+	default:
+		return retryDue(record, r.previousRun, now)
+	}
+}
+
+// setNextChecks schedules the next check of the selected records.
+// The same random jitter is used for all the records, so records
+// sharing the same schedule are still checked in the same pass.
+func (r *Runner) setNextChecks(records []librecords.Record,
+	now time.Time, selected recordSelector) {
+	var jitter time.Duration
+	if r.jitter > 0 {
+		jitter = time.Duration(r.randInt63n(int64(r.jitter)))
+	}
+	r.stateMutex.Lock()
+	defer r.stateMutex.Unlock()
+	for _, record := range records {
+		if !selected(record, now) {
+			continue
+		}
+		next := r.scheduleOf(record).Next(now)
+		if !next.IsZero() { // zero for a cron schedule never activating
+			next = next.Add(jitter)
+		}
+		r.nextChecks[record.ID] = next
+	}
+}
+
+func (r *Runner) scheduleOf(record librecords.Record) Scheduler { //nolint:ireturn
+	period := settings.GetCommon(record.Settings).Period
+	if period == 0 {
+		return r.schedule
+	}
+	return Periodic(period)
+}
+
+func (r *Runner) cooldownOf(record librecords.Record) time.Duration {
+	cooldown := settings.GetCommon(record.Settings).Cooldown
+	if cooldown == nil {
+		return r.cooldown
+	}
+	return *cooldown
+}
+
+// nextRunTime returns the earliest time at which a record is due,
+// which is now if a record is already due.
+func (r *Runner) nextRunTime(now time.Time) (next time.Time) {
+	for _, record := range r.db.SelectAll() {
+		nextCheck, ok := r.nextChecks[record.ID]
+		switch {
+		case !ok: // never checked
+			return now
+		case nextCheck.IsZero(): // never scheduled
+			continue
+		}
+		if next.IsZero() || nextCheck.Before(next) {
+			next = nextCheck
+		}
+	}
+
+	if nextRetry := r.nextRetryTime(now); !nextRetry.IsZero() &&
+		(next.IsZero() || nextRetry.Before(next)) {
+		next = nextRetry
+	}
+
+	switch {
+	case next.IsZero(): // no record to check
+		const idlePeriod = time.Hour
+		return now.Add(idlePeriod)
+	case next.Before(now):
+		return now
+	default:
+		return next
+	}
+}
+
+// nextRetryTime returns the earliest future backoff retry time
+// of all failed records, or the zero time if there is none.
+func (r *Runner) nextRetryTime(now time.Time) (next time.Time) {
+	for _, record := range r.db.SelectAll() {
+		retryTime := record.Backoff.RetryTime
+		if record.Status != constants.FAIL || !record.Backoff.IsWithin(now) {
+			continue
+		}
+		if next.IsZero() || retryTime.Before(next) {
+			next = retryTime
+		}
+	}
+	return next
+}
+
+// Periodic is a Scheduler checking records at a fixed period.
+type Periodic time.Duration
+
+func (p Periodic) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(p))
+}
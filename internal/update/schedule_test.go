@@ -0,0 +1,219 @@
+package update
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/constants"
+	"github.com/qdm12/ddns-updater/internal/models"
+	librecords "github.com/qdm12/ddns-updater/internal/records"
+	"github.com/qdm12/ddns-updater/internal/settings"
+	"github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Runner_isDue(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	previousRun := now.Add(-time.Hour)
+
+	testCases := map[string]struct {
+		record    librecords.Record
+		nextCheck *time.Time
+		due       bool
+	}{
+		"never checked": {
+			record: librecords.Record{ID: "a"},
+			due:    true,
+		},
+		"next check reached": {
+			record:    librecords.Record{ID: "a"},
+			nextCheck: ptrTo(now),
+			due:       true,
+		},
+		"next check not reached": {
+			record:    librecords.Record{ID: "a"},
+			nextCheck: ptrTo(now.Add(time.Second)),
+		},
+		"never scheduled": {
+			record:    librecords.Record{ID: "a"},
+			nextCheck: &time.Time{},
+		},
+		"retry reached since previous run": {
+			record: librecords.Record{ID: "a", Status: constants.FAIL,
+				Backoff: models.Backoff{Failures: 1, RetryTime: now.Add(-time.Minute)}},
+			nextCheck: ptrTo(now.Add(time.Hour)),
+			due:       true,
+		},
+		"retry reached before previous run": {
+			record: librecords.Record{ID: "a", Status: constants.FAIL,
+				Backoff: models.Backoff{Failures: 1, RetryTime: previousRun.Add(-time.Minute)}},
+			nextCheck: ptrTo(now.Add(time.Hour)),
+		},
+		"retry not reached": {
+			record: librecords.Record{ID: "a", Status: constants.FAIL,
+				Backoff: models.Backoff{Failures: 1, RetryTime: now.Add(time.Minute)}},
+			nextCheck: ptrTo(now.Add(time.Hour)),
+		},
+		"retry time of record not failed": {
+			record: librecords.Record{ID: "a", Status: constants.DISABLED,
+				Backoff: models.Backoff{Failures: 1, RetryTime: now.Add(-time.Minute)}},
+			nextCheck: ptrTo(now.Add(time.Hour)),
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &Runner{
+				nextChecks:  map[string]time.Time{},
+				previousRun: previousRun,
+			}
+			if testCase.nextCheck != nil {
+				runner.nextChecks["a"] = *testCase.nextCheck
+			}
+
+			due := runner.isDue(testCase.record, now)
+
+			assert.Equal(t, testCase.due, due)
+		})
+	}
+}
+
+func Test_Runner_isDue_retriedOnce(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	record := librecords.Record{
+		ID:       "a",
+		Settings: &testSettings{ipVersion: ipversion.IP4},
+		Status:   constants.FAIL,
+		Backoff:  models.Backoff{Failures: 1, RetryTime: now.Add(-time.Minute)},
+	}
+	db := &testDatabase{records: map[string]librecords.Record{"a": record}}
+	runner := NewRunner(db, nil, nil, nil, Periodic(time.Hour),
+		0, nil, 0, 1, 1, false, noopLogger{}, nil, func() time.Time { return now })
+	runner.nextChecks["a"] = now.Add(time.Hour)
+	runner.lastRun = now.Add(-2 * time.Minute)
+
+	// first pass after the retry time
+	runner.previousRun, runner.lastRun = runner.lastRun, now
+	assert.True(t, runner.isDue(record, now))
+
+	// record still failed with the same retry time on the next pass
+	now = now.Add(time.Minute)
+	runner.previousRun, runner.lastRun = runner.lastRun, now
+	assert.False(t, runner.isDue(record, now))
+}
+
+func Test_Runner_nextRunTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		records    []librecords.Record
+		nextChecks map[string]time.Time
+		next       time.Time
+	}{
+		"no record": {
+			next: now.Add(time.Hour),
+		},
+		"record never checked": {
+			records: []librecords.Record{{ID: "a"}, {ID: "b"}},
+			nextChecks: map[string]time.Time{
+				"a": now.Add(time.Minute),
+			},
+			next: now,
+		},
+		"earliest next check": {
+			records: []librecords.Record{{ID: "a"}, {ID: "b"}, {ID: "c"}},
+			nextChecks: map[string]time.Time{
+				"a": now.Add(2 * time.Minute),
+				"b": now.Add(time.Minute),
+				"c": {}, // never scheduled
+			},
+			next: now.Add(time.Minute),
+		},
+		"next check in the past": {
+			records: []librecords.Record{{ID: "a"}},
+			nextChecks: map[string]time.Time{
+				"a": now.Add(-time.Minute),
+			},
+			next: now,
+		},
+		"earlier retry time": {
+			records: []librecords.Record{{ID: "a", Status: constants.FAIL,
+				Backoff: models.Backoff{Failures: 1, RetryTime: now.Add(time.Minute)}}},
+			nextChecks: map[string]time.Time{
+				"a": now.Add(time.Hour),
+			},
+			next: now.Add(time.Minute),
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := &testDatabase{records: make(map[string]librecords.Record)}
+			for _, record := range testCase.records {
+				db.records[record.ID] = record
+			}
+			runner := &Runner{db: db, nextChecks: testCase.nextChecks}
+
+			next := runner.nextRunTime(now)
+
+			assert.Equal(t, testCase.next, next)
+		})
+	}
+}
+
+func Test_Runner_scheduleOf_cooldownOf(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	zero := time.Duration(0)
+	runner := &Runner{schedule: Periodic(time.Hour), cooldown: time.Minute}
+
+	testCases := map[string]struct {
+		common   settings.Common
+		next     time.Time
+		cooldown time.Duration
+	}{
+		"program defaults": {
+			next:     now.Add(time.Hour),
+			cooldown: time.Minute,
+		},
+		"record period and cooldown": {
+			common:   settings.Common{Period: 5 * time.Minute, Cooldown: ptrTo(time.Hour)},
+			next:     now.Add(5 * time.Minute),
+			cooldown: time.Hour,
+		},
+		"record zero cooldown": {
+			common:   settings.Common{Cooldown: &zero},
+			next:     now.Add(time.Hour),
+			cooldown: 0,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			record := librecords.Record{
+				Settings: settings.WithCommon(&testSettings{}, testCase.common),
+			}
+
+			assert.Equal(t, testCase.next, runner.scheduleOf(record).Next(now))
+			assert.Equal(t, testCase.cooldown, runner.cooldownOf(record))
+		})
+	}
+}
+
+func ptrTo[T any](value T) *T { return &value }
@@ -0,0 +1,76 @@
+package update
+
+import (
+	"net"
+	"time"
+)
+
+// State is the state of the runner.
+type State struct {
+	// NextRun is the time of the next scheduled check of records,
+	// and is the zero time if the runner is not running.
+	NextRun time.Time `json:"next_run"`
+	// LastRun is the time of the last check of records,
+	// and is the zero time if no record was checked yet.
+	LastRun time.Time `json:"last_run"`
+	// NextChecks maps record identifiers to the time of their next
+	// scheduled check, which is the zero time if never scheduled.
+	NextChecks map[string]time.Time `json:"next_checks"`
+	// PublicIPs are the public IP addresses last detected.
+	PublicIPs PublicIPs `json:"public_ips"`
+}
+
+// PublicIPs are the public IP addresses last detected,
+// each of them being nil if never detected.
+type PublicIPs struct {
+	// IP is the public IP address detected for records
+	// with the IP version "ipv4 or ipv6".
+	IP   *DetectedIP `json:"ip,omitempty"`
+	IPv4 *DetectedIP `json:"ipv4,omitempty"`
+	IPv6 *DetectedIP `json:"ipv6,omitempty"`
+}
+
+// DetectedIP is a public IP address detected at a certain time.
+type DetectedIP struct {
+	IP   net.IP    `json:"ip"`
+	Time time.Time `json:"time"`
+}
+
+// State returns the current state of the runner.
+// It is safe to call it concurrently with Run.
+func (r *Runner) State() (state State) {
+	r.stateMutex.RLock()
+	defer r.stateMutex.RUnlock()
+	state = State{
+		NextRun:    r.nextRun,
+		LastRun:    r.lastRun,
+		NextChecks: make(map[string]time.Time, len(r.nextChecks)),
+		PublicIPs:  r.publicIPs,
+	}
+	for id, nextCheck := range r.nextChecks {
+		state.NextChecks[id] = nextCheck
+	}
+	return state
+}
+
+// setPublicIPs sets the public IP addresses detected at the
+// time given, keeping previous IP addresses not detected.
+func (r *Runner) setPublicIPs(ip, ipv4, ipv6 net.IP, now time.Time) {
+	r.stateMutex.Lock()
+	defer r.stateMutex.Unlock()
+	if ip != nil {
+		r.publicIPs.IP = &DetectedIP{IP: ip, Time: now}
+	}
+	if ipv4 != nil {
+		r.publicIPs.IPv4 = &DetectedIP{IP: ipv4, Time: now}
+	}
+	if ipv6 != nil {
+		r.publicIPs.IPv6 = &DetectedIP{IP: ipv6, Time: now}
+	}
+}
+
+func (r *Runner) setNextRun(nextRun time.Time) {
+	r.stateMutex.Lock()
+	defer r.stateMutex.Unlock()
+	r.nextRun = nextRun
+}
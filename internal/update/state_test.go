@@ -0,0 +1,40 @@
+package update
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Runner_State(t *testing.T) {
+	t.Parallel()
+
+	t1 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	runner := &Runner{
+		nextRun:    t2,
+		lastRun:    t1,
+		nextChecks: map[string]time.Time{"a": t2},
+	}
+
+	runner.setPublicIPs(nil, net.IPv4(1, 2, 3, 4), net.ParseIP("::1"), t1)
+	runner.setPublicIPs(nil, net.IPv4(5, 6, 7, 8), nil, t2)
+	state := runner.State()
+
+	expected := State{
+		NextRun:    t2,
+		LastRun:    t1,
+		NextChecks: map[string]time.Time{"a": t2},
+		PublicIPs: PublicIPs{
+			IPv4: &DetectedIP{IP: net.IPv4(5, 6, 7, 8), Time: t2},
+			IPv6: &DetectedIP{IP: net.ParseIP("::1"), Time: t1},
+		},
+	}
+	assert.Equal(t, expected, state)
+
+	// the state returned is a copy
+	state.NextChecks["a"] = t1
+	assert.Equal(t, t2, runner.State().NextChecks["a"])
+}
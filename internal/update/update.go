@@ -2,7 +2,6 @@ package update
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -14,25 +13,32 @@ import (
 )
 
 type Updater struct {
-	db     Database
-	client *http.Client
-	notify notifyFunc
-	logger DebugLogger
+	db      Database
+	client  *http.Client
+	backoff *Backoff
+	notify  notifyFunc
+	logger  DebugLogger
 }
 
 type notifyFunc func(message string)
 
-func NewUpdater(db Database, client *http.Client, notify notifyFunc, logger DebugLogger) *Updater {
+// banPeriod is the duration during which no update is attempted
+// for a record after the provider reported an abuse.
+const banPeriod = time.Hour
+
+func NewUpdater(db Database, client *http.Client, backoff *Backoff,
+	notify notifyFunc, logger DebugLogger) *Updater {
 	client = makeLogClient(client, logger)
 	return &Updater{
-		db:     db,
-		client: client,
-		notify: notify,
-		logger: logger,
+		db:      db,
+		client:  client,
+		backoff: backoff,
+		notify:  notify,
+		logger:  logger,
 	}
 }
 
-func (u *Updater) Update(ctx context.Context, id uint, ip net.IP, now time.Time) (err error) {
+func (u *Updater) Update(ctx context.Context, id string, ip net.IP, now time.Time) (err error) {
 	record, err := u.db.Select(id)
 	if err != nil {
 		return err
@@ -47,16 +53,31 @@ func (u *Updater) Update(ctx context.Context, id uint, ip net.IP, now time.Time)
 	newIP, err := record.Settings.Update(ctx, u.client, ip)
 	if err != nil {
 		record.Message = err.Error()
-		if errors.Is(err, settingserrors.ErrAbuse) {
+		record.ErrorCategory = settingserrors.Categorize(err)
+		lastFailure := now
+		record.LastFailure = &lastFailure
+		record.LastError = record.Message
+		record.Backoff = u.nextBackoff(record.Backoff, now)
+		record.LastBan = nil // clear a previous ban
+		domainName := record.Settings.BuildDomainName()
+		switch {
+		case record.ErrorCategory.IsPermanent():
+			record.Status = constants.DISABLED
+			record.Backoff.RetryTime = time.Time{}
+			message := domainName + ": " + record.Message + ", record is disabled due to " +
+				string(record.ErrorCategory) + " error until the program is restarted"
+			u.notify(message)
+			err = fmt.Errorf("%w: for domain %s, record is disabled", err, domainName)
+		case record.ErrorCategory == settingserrors.CategoryRateLimited:
 			lastBan := time.Unix(now.Unix(), 0)
 			record.LastBan = &lastBan
-			domainName := record.Settings.BuildDomainName()
+			if banEnd := lastBan.Add(banPeriod); record.Backoff.RetryTime.Before(banEnd) {
+				record.Backoff.RetryTime = banEnd
+			}
 			message := domainName + ": " + record.Message +
 				", no more updates will be attempted for an hour"
 			u.notify(message)
 			err = fmt.Errorf("%w: for domain %s, no more update will be attempted for 1h", err, domainName)
-		} else {
-			record.LastBan = nil // clear a previous ban
 		}
 		if updateErr := u.db.Update(id, record); updateErr != nil {
 			return fmt.Errorf("%w (with database update error: %w)", err, updateErr)
@@ -64,6 +85,8 @@ func (u *Updater) Update(ctx context.Context, id uint, ip net.IP, now time.Time)
 		return err
 	}
 	record.Status = constants.SUCCESS
+	record.Backoff = models.Backoff{}
+	record.ErrorCategory = ""
 	record.Message = fmt.Sprintf("changed to %s", ip.String())
 	record.History = append(record.History, models.HistoryEvent{
 		IP:   newIP,
@@ -72,3 +95,13 @@ func (u *Updater) Update(ctx context.Context, id uint, ip net.IP, now time.Time)
 	u.notify(record.Settings.BuildDomainName() + " " + record.Message)
 	return u.db.Update(id, record) // persists some data if needed (i.e new IP)
 }
+
+// nextBackoff returns the backoff state following a new update failure.
+func (u *Updater) nextBackoff(backoff models.Backoff, now time.Time) models.Backoff {
+	backoff.Failures++
+	backoff.RetryTime = time.Time{}
+	if delay := u.backoff.Delay(backoff.Failures); delay > 0 {
+		backoff.RetryTime = now.Add(delay)
+	}
+	return backoff
+}
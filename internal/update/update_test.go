@@ -0,0 +1,145 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/constants"
+	"github.com/qdm12/ddns-updater/internal/models"
+	librecords "github.com/qdm12/ddns-updater/internal/records"
+	settingserrors "github.com/qdm12/ddns-updater/internal/settings/errors"
+	"github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Updater_Update(t *testing.T) {
+	t.Parallel()
+
+	// the ban time is set in the local time zone
+	now := time.Unix(1577836800, 0)
+	ip := net.IPv4(1, 2, 3, 4)
+	errTransient := errors.New("dial tcp: connection refused")
+
+	testCases := map[string]struct {
+		backoff       models.Backoff
+		updateErr     error
+		maxBackoff    time.Duration
+		errWrapped    error
+		status        models.Status
+		category      settingserrors.Category
+		expectBackoff models.Backoff
+		lastBan       *time.Time
+		notifications int
+	}{
+		"success resets backoff": {
+			backoff:       models.Backoff{Failures: 3, RetryTime: now},
+			maxBackoff:    time.Hour,
+			status:        constants.SUCCESS,
+			notifications: 1,
+		},
+		"transient error grows backoff": {
+			backoff:    models.Backoff{Failures: 1},
+			updateErr:  errTransient,
+			maxBackoff: time.Hour,
+			errWrapped: errTransient,
+			status:     constants.FAIL,
+			category:   settingserrors.CategoryTransient,
+			// delay of 2m for the second failure, half of it randomized
+			expectBackoff: models.Backoff{Failures: 2, RetryTime: now.Add(time.Minute)},
+		},
+		"provider error grows backoff": {
+			updateErr:     fmt.Errorf("%w: 500", settingserrors.ErrBadHTTPStatus),
+			maxBackoff:    time.Hour,
+			errWrapped:    settingserrors.ErrBadHTTPStatus,
+			status:        constants.FAIL,
+			category:      settingserrors.CategoryProvider,
+			expectBackoff: models.Backoff{Failures: 1, RetryTime: now.Add(30 * time.Second)},
+		},
+		"rate limited retries after the ban": {
+			updateErr:     settingserrors.ErrAbuse,
+			maxBackoff:    time.Hour,
+			errWrapped:    settingserrors.ErrAbuse,
+			status:        constants.FAIL,
+			category:      settingserrors.CategoryRateLimited,
+			expectBackoff: models.Backoff{Failures: 1, RetryTime: now.Add(banPeriod)},
+			lastBan:       &now,
+			notifications: 1,
+		},
+		"rate limited keeps a backoff longer than the ban": {
+			backoff:       models.Backoff{Failures: 10},
+			updateErr:     settingserrors.ErrAbuse,
+			maxBackoff:    4 * time.Hour,
+			errWrapped:    settingserrors.ErrAbuse,
+			status:        constants.FAIL,
+			category:      settingserrors.CategoryRateLimited,
+			expectBackoff: models.Backoff{Failures: 11, RetryTime: now.Add(2 * time.Hour)},
+			lastBan:       &now,
+			notifications: 1,
+		},
+		"authentication error disables record": {
+			updateErr:     fmt.Errorf("%w: bad token", settingserrors.ErrAuth),
+			maxBackoff:    time.Hour,
+			errWrapped:    settingserrors.ErrAuth,
+			status:        constants.DISABLED,
+			category:      settingserrors.CategoryAuth,
+			expectBackoff: models.Backoff{Failures: 1},
+			notifications: 1,
+		},
+		"configuration error disables record": {
+			updateErr:     settingserrors.ErrZoneNotFound,
+			maxBackoff:    time.Hour,
+			errWrapped:    settingserrors.ErrZoneNotFound,
+			status:        constants.DISABLED,
+			category:      settingserrors.CategoryConfig,
+			expectBackoff: models.Backoff{Failures: 1},
+			notifications: 1,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := &testDatabase{records: map[string]librecords.Record{
+				"a": {
+					ID: "a",
+					Settings: &testSettings{ipVersion: ipversion.IP4,
+						updateErr: testCase.updateErr},
+					Backoff: testCase.backoff,
+				},
+			}}
+			backoff := NewBackoff(time.Minute, testCase.maxBackoff)
+			backoff.randInt63n = func(int64) int64 { return 0 }
+			notifications := 0
+			notify := func(string) { notifications++ }
+			updater := NewUpdater(db, &http.Client{}, backoff, notify, noopLogger{})
+
+			err := updater.Update(context.Background(), "a", ip, now)
+
+			if testCase.updateErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, testCase.errWrapped)
+			}
+			record := db.records["a"]
+			assert.Equal(t, testCase.status, record.Status)
+			assert.Equal(t, testCase.category, record.ErrorCategory)
+			assert.Equal(t, testCase.expectBackoff, record.Backoff)
+			assert.Equal(t, testCase.lastBan, record.LastBan)
+			assert.Equal(t, testCase.notifications, notifications)
+			if testCase.updateErr == nil {
+				assert.Equal(t, ip, record.History.GetCurrentIP())
+			} else {
+				assert.Equal(t, &now, record.LastFailure)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package publicip
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/qdm12/ddns-updater/pkg/publicip/dns"
+	"github.com/qdm12/ddns-updater/pkg/publicip/http"
+	"github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
+)
+
+// Check is the result of fetching the public IP address
+// from a single provider for an IP version.
+type Check struct {
+	// Fetcher is the fetcher type, "dns" or "http".
+	Fetcher  string
+	Provider string
+	Version  ipversion.IPVersion
+	IP       net.IP
+	Latency  time.Duration
+	Err      error
+}
+
+// CheckAll fetches the public IP address from every provider
+// of every fetcher configured, for each IP version.
+// It is meant to diagnose the providers configured.
+func (f *Fetcher) CheckAll(ctx context.Context) (checks []Check) {
+	for _, fetcher := range f.fetchers {
+		switch typedFetcher := fetcher.(type) {
+		case *dns.Fetcher:
+			for _, result := range typedFetcher.CheckAll(ctx) {
+				checks = append(checks, Check{
+					Fetcher:  "dns",
+					Provider: string(result.Provider),
+					Version:  result.Version,
+					IP:       result.IP,
+					Latency:  result.Latency,
+					Err:      result.Err,
+				})
+			}
+		case *http.Fetcher:
+			for _, result := range typedFetcher.CheckAll(ctx) {
+				checks = append(checks, Check{
+					Fetcher:  "http",
+					Provider: result.URL,
+					Version:  result.Version,
+					IP:       result.IP,
+					Latency:  result.Latency,
+					Err:      result.Err,
+				})
+			}
+		}
+	}
+	return checks
+}
+
+// DistinctIPs returns the distinct IPv4 and IPv6 addresses found in
+// the successful checks given. More than one IPv4 address or more than
+// one IPv6 address means the providers disagree on the public IP address.
+func DistinctIPs(checks []Check) (ipv4s, ipv6s []net.IP) {
+	seen := make(map[string]struct{}, len(checks))
+	for _, check := range checks {
+		if check.Err != nil || check.IP == nil {
+			continue
+		}
+		key := check.IP.String()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		if check.IP.To4() != nil {
+			ipv4s = append(ipv4s, check.IP)
+		} else {
+			ipv6s = append(ipv6s, check.IP)
+		}
+	}
+	return ipv4s, ipv6s
+}
+
+// MajorityIPs returns the IPv4 and IPv6 addresses found by the most
+// successful checks. An address is nil if no check found an address
+// for its version, or if several addresses are found by the same
+// highest number of checks, since there is no majority to trust.
+func MajorityIPs(checks []Check) (ipv4, ipv6 net.IP) {
+	ipv4s, ipv6s := DistinctIPs(checks)
+	counts := make(map[string]int, len(ipv4s)+len(ipv6s))
+	for _, check := range checks {
+		if check.Err != nil || check.IP == nil {
+			continue
+		}
+		counts[check.IP.String()]++
+	}
+	return majorityIP(ipv4s, counts), majorityIP(ipv6s, counts)
+}
+
+func majorityIP(ips []net.IP, counts map[string]int) (majority net.IP) {
+	maxCount := 0
+	for _, ip := range ips {
+		count := counts[ip.String()]
+		switch {
+		case count > maxCount:
+			majority, maxCount = ip, count
+		case count == maxCount:
+			majority = nil
+		}
+	}
+	return majority
+}
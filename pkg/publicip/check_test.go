@@ -0,0 +1,122 @@
+package publicip
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DistinctIPs(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		checks []Check
+		ipv4s  []net.IP
+		ipv6s  []net.IP
+	}{
+		"no check": {},
+		"failed checks ignored": {
+			checks: []Check{
+				{IP: net.IP{1, 2, 3, 4}, Err: errors.New("test")},
+				{},
+			},
+		},
+		"agreement": {
+			checks: []Check{
+				{IP: net.IP{1, 2, 3, 4}},
+				{IP: net.ParseIP("1.2.3.4")},
+				{IP: net.ParseIP("::1")},
+			},
+			ipv4s: []net.IP{{1, 2, 3, 4}},
+			ipv6s: []net.IP{net.ParseIP("::1")},
+		},
+		"disagreement": {
+			checks: []Check{
+				{IP: net.IP{1, 2, 3, 4}},
+				{IP: net.IP{5, 6, 7, 8}},
+				{IP: net.IP{1, 2, 3, 4}},
+			},
+			ipv4s: []net.IP{{1, 2, 3, 4}, {5, 6, 7, 8}},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ipv4s, ipv6s := DistinctIPs(testCase.checks)
+
+			assert.Equal(t, testCase.ipv4s, ipv4s)
+			assert.Equal(t, testCase.ipv6s, ipv6s)
+		})
+	}
+}
+
+func Test_MajorityIPs(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		checks []Check
+		ipv4   net.IP
+		ipv6   net.IP
+	}{
+		"no check": {},
+		"failed checks ignored": {
+			checks: []Check{
+				{IP: net.IP{1, 2, 3, 4}, Err: errors.New("test")},
+				{},
+				{IP: net.IP{5, 6, 7, 8}},
+			},
+			ipv4: net.IP{5, 6, 7, 8},
+		},
+		"agreement": {
+			checks: []Check{
+				{IP: net.IP{1, 2, 3, 4}},
+				{IP: net.ParseIP("1.2.3.4")},
+				{IP: net.ParseIP("::1")},
+			},
+			ipv4: net.IP{1, 2, 3, 4},
+			ipv6: net.ParseIP("::1"),
+		},
+		"majority": {
+			checks: []Check{
+				{IP: net.IP{5, 6, 7, 8}},
+				{IP: net.IP{1, 2, 3, 4}},
+				{IP: net.IP{1, 2, 3, 4}},
+			},
+			ipv4: net.IP{1, 2, 3, 4},
+		},
+		"tie": {
+			checks: []Check{
+				{IP: net.IP{1, 2, 3, 4}},
+				{IP: net.IP{5, 6, 7, 8}},
+				{IP: net.ParseIP("::1")},
+			},
+			ipv6: net.ParseIP("::1"),
+		},
+		"tie after majority": {
+			checks: []Check{
+				{IP: net.IP{1, 2, 3, 4}},
+				{IP: net.IP{1, 2, 3, 4}},
+				{IP: net.IP{5, 6, 7, 8}},
+				{IP: net.IP{5, 6, 7, 8}},
+				{IP: net.IP{9, 9, 9, 9}},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ipv4, ipv6 := MajorityIPs(testCase.checks)
+
+			assert.Equal(t, testCase.ipv4, ipv4)
+			assert.Equal(t, testCase.ipv6, ipv6)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
+)
+
+// Result is the result of fetching the public IP
+// address from a single provider for an IP version.
+type Result struct {
+	Provider Provider
+	Version  ipversion.IPVersion
+	IP       net.IP
+	Latency  time.Duration
+	Err      error
+}
+
+// CheckAll fetches the public IP address from every provider configured,
+// for each IP version, bypassing the cycling mechanism.
+// It is meant to diagnose the providers configured.
+func (f *Fetcher) CheckAll(ctx context.Context) (results []Result) {
+	versionToClient := []struct {
+		version ipversion.IPVersion
+		client  Client
+	}{
+		{version: ipversion.IP4or6, client: f.client},
+		{version: ipversion.IP4, client: f.client4},
+		{version: ipversion.IP6, client: f.client6},
+	}
+
+	clients := make([]Client, 0, len(versionToClient)*len(f.ring.providers))
+	for _, versionClient := range versionToClient {
+		for _, provider := range f.ring.providers {
+			results = append(results, Result{Provider: provider, Version: versionClient.version})
+			clients = append(clients, versionClient.client)
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(results))
+	for i := range results {
+		go func(result *Result, client Client) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, f.timeout)
+			defer cancel()
+			start := time.Now()
+			result.IP, result.Err = fetch(ctx, client, result.Provider.data())
+			result.Latency = time.Since(start)
+		}(&results[i], clients[i])
+	}
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,41 @@
+package dns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type blockingClient struct{}
+
+func (blockingClient) ExchangeContext(ctx context.Context, _ *dns.Msg, _ string) (
+	r *dns.Msg, rtt time.Duration, err error) {
+	<-ctx.Done()
+	return nil, 0, ctx.Err()
+}
+
+func Test_Fetcher_CheckAll_timeout(t *testing.T) {
+	t.Parallel()
+
+	fetcher := &Fetcher{
+		ring: ring{
+			counter:   new(uint32),
+			providers: []Provider{Cloudflare},
+		},
+		client:  blockingClient{},
+		client4: blockingClient{},
+		client6: blockingClient{},
+		timeout: time.Millisecond,
+	}
+
+	results := fetcher.CheckAll(context.Background())
+
+	require.Len(t, results, 3)
+	for _, result := range results {
+		assert.ErrorIs(t, result.Err, context.DeadlineExceeded)
+	}
+}
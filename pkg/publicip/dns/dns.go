@@ -2,6 +2,7 @@ package dns
 
 import (
 	"net"
+	"time"
 
 	"github.com/miekg/dns"
 )
@@ -11,6 +12,7 @@ type Fetcher struct {
 	client  Client
 	client4 Client
 	client6 Client
+	timeout time.Duration
 }
 
 type ring struct {
@@ -52,5 +54,6 @@ func New(options ...Option) (f *Fetcher, err error) {
 			Dialer:  dialer,
 			Timeout: settings.timeout,
 		},
+		timeout: settings.timeout,
 	}, nil
 }
@@ -19,4 +19,5 @@ func Test_New(t *testing.T) {
 	assert.NotNil(t, impl.client)
 	assert.NotNil(t, impl.client4)
 	assert.NotNil(t, impl.client6)
+	assert.Equal(t, time.Hour, impl.timeout)
 }
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
+)
+
+// Result is the result of fetching the public IP
+// address from a single URL for an IP version.
+type Result struct {
+	URL     string
+	Version ipversion.IPVersion
+	IP      net.IP
+	Latency time.Duration
+	Err     error
+}
+
+// CheckAll fetches the public IP address from every URL configured,
+// for each IP version, bypassing the cycling and ban mechanisms.
+// It is meant to diagnose the providers configured.
+func (f *Fetcher) CheckAll(ctx context.Context) (results []Result) {
+	for _, ring := range []struct {
+		ring    *urlsRing
+		version ipversion.IPVersion
+	}{
+		{ring: f.ip4or6, version: ipversion.IP4or6},
+		{ring: f.ip4, version: ipversion.IP4},
+		{ring: f.ip6, version: ipversion.IP6},
+	} {
+		for _, url := range ring.ring.urls {
+			results = append(results, Result{URL: url, Version: ring.version})
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(results))
+	for i := range results {
+		go func(result *Result) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, f.timeout)
+			defer cancel()
+			start := time.Now()
+			result.IP, result.Err = fetch(ctx, f.client, result.URL, result.Version)
+			result.Latency = time.Since(start)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	return results
+}